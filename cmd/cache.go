@@ -0,0 +1,38 @@
+package main
+
+import (
+	"github.com/karambo3a/wbtech_test_task/internal/cache"
+	"github.com/karambo3a/wbtech_test_task/internal/config"
+	"github.com/karambo3a/wbtech_test_task/internal/metrics"
+)
+
+// newOrderCache builds the cache selected by cfg.Cache.Backend. The circuit breaker
+// in front of Redis is also returned for health checks, it is nil for the memory backend.
+func newOrderCache(cfg *config.Config) (cache.OrderCache, *cache.CircuitBreakerCache, error) {
+	if cfg.Cache.Backend == "memory" {
+		memory := cache.NewMemoryCache(cfg.Cache.LocalMaxEntries, int64(cfg.Cache.LocalMaxMB)<<20)
+		registerLocalCache(memory)
+		return memory, nil, nil
+	}
+
+	redisCache, err := cache.NewRedisCache(cfg.Redis)
+	if err != nil {
+		return nil, nil, err
+	}
+	breaker := cache.NewCircuitBreakerCache(redisCache, cfg.Cache.BreakerFailures, cfg.Cache.BreakerCooldown)
+	metrics.RegisterCacheBreaker(func() float64 { return float64(breaker.BreakerStats().State) })
+	if cfg.Cache.LocalMaxEntries == 0 {
+		return breaker, breaker, nil
+	}
+
+	tiered := cache.NewTieredCache(breaker, cfg.Cache.LocalMaxEntries, int64(cfg.Cache.LocalMaxMB)<<20, cfg.Cache.LocalTTL)
+	registerLocalCache(tiered)
+	return tiered, breaker, nil
+}
+
+func registerLocalCache(c cache.OrderCache) {
+	metrics.RegisterLocalCache(
+		func() float64 { return float64(c.Stats().Entries) },
+		func() float64 { return float64(c.Stats().Bytes) },
+	)
+}
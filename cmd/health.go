@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/karambo3a/wbtech_test_task/internal/cache"
+	"github.com/karambo3a/wbtech_test_task/internal/config"
+	"github.com/karambo3a/wbtech_test_task/internal/consumer"
+	"github.com/karambo3a/wbtech_test_task/internal/health"
+	"github.com/karambo3a/wbtech_test_task/internal/service"
+)
+
+// newHealth registers the readiness checks. Orders are read from Postgres when Redis is down
+// or not warmed up yet and Kafka only feeds new orders, so these leave the service ready but degraded.
+// breaker is nil for the memory cache backend.
+func newHealth(cfg *config.Config, db *sqlx.DB, breaker *cache.CircuitBreakerCache, consumer consumer.Consumer, svc *service.Service) *health.Health {
+	h := health.NewHealth(cfg.Server.HealthTimeout)
+
+	h.Add(health.Check{
+		Name:     "postgres",
+		Critical: true,
+		Run: func(ctx context.Context) (map[string]any, error) {
+			return nil, db.PingContext(ctx)
+		},
+	})
+	h.Add(health.Check{
+		Name: "cache_warmup",
+		Run: func(ctx context.Context) (map[string]any, error) {
+			progress := svc.WarmupProgress()
+			details := map[string]any{
+				"state":    progress.State,
+				"strategy": progress.Strategy,
+				"limit":    progress.Limit,
+				"cached":   progress.Cached,
+				"failed":   progress.Failed,
+			}
+			switch progress.State {
+			case service.WarmupRunning:
+				return details, errors.New("cache warm-up is in progress")
+			case service.WarmupFailed:
+				return details, errors.New(progress.Error)
+			}
+			return details, nil
+		},
+	})
+	// the memory backend has nothing to check
+	if breaker != nil {
+		h.Add(health.Check{
+			Name: "redis",
+			Run: func(ctx context.Context) (map[string]any, error) {
+				stats := breaker.BreakerStats()
+				details := map[string]any{
+					"breaker_state":        stats.State.String(),
+					"consecutive_failures": stats.ConsecutiveFailures,
+					"rejected":             stats.Rejected,
+				}
+				return details, breaker.Ping(ctx)
+			},
+		})
+	}
+	h.Add(health.Check{
+		Name: "kafka",
+		Run: func(ctx context.Context) (map[string]any, error) {
+			lag := consumer.Lag()
+			details := map[string]any{"lag": lag}
+			if err := consumer.Ping(ctx); err != nil {
+				return details, err
+			}
+			if cfg.Kafka.MaxLag > 0 && lag > cfg.Kafka.MaxLag {
+				return details, fmt.Errorf("consumer lag %d exceeds %d", lag, cfg.Kafka.MaxLag)
+			}
+			return details, nil
+		},
+	})
+	return h
+}
@@ -1,42 +1,129 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
-	"github.com/karambo3a/wbtech_test_task/internal/cache"
+	"github.com/karambo3a/wbtech_test_task/internal/config"
 	"github.com/karambo3a/wbtech_test_task/internal/consumer"
 	"github.com/karambo3a/wbtech_test_task/internal/handlers"
+	"github.com/karambo3a/wbtech_test_task/internal/logger"
+	"github.com/karambo3a/wbtech_test_task/internal/metrics"
 	"github.com/karambo3a/wbtech_test_task/internal/repository"
 	"github.com/karambo3a/wbtech_test_task/internal/service"
 )
 
 func main() {
-	log.SetFlags(log.Lshortfile)
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		fatal("failed to load config", err)
+	}
+	appLogger, err := logger.New(os.Stdout, cfg.Log)
+	if err != nil {
+		fatal("failed to create logger", err)
+	}
+	// the standard log package used by libraries is written through the same handler
+	slog.SetDefault(appLogger)
+	slog.Info("effective config", "config", cfg)
 
-	db, err := repository.NewPostgresDB()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	db, err := repository.NewPostgresDB(cfg.Database)
 	if err != nil {
-		log.Fatalf("failed connect to db: %s", err.Error())
+		fatal("failed to connect to db", err)
 	}
+	slog.Info("connected to db")
 
-	defer db.Close()
-	log.Println("connected to data base")
+	if len(args) > 0 {
+		err := runCommand(ctx, db, args)
+		_ = db.Close()
+		if err != nil {
+			fatal("command failed", err)
+		}
+		return
+	}
 
-	repository := repository.NewRepository(db)
-	log.Println("repository created")
+	if cfg.Database.MigrateOnStart {
+		m, err := newMigrator(db)
+		if err != nil {
+			fatal("failed to load migrations", err)
+		}
+		if _, err := m.Up(ctx); err != nil {
+			fatal("failed to migrate db", err)
+		}
+		slog.Info("db migrated")
+	}
 
-	consumer := consumer.NewConsumer()
-	cache := cache.NewRedisCache(50)
-	service := service.NewService(repository, consumer, cache, int64(100))
-	log.Println("service created")
-	defer service.CloseConsumer()
+	repository, err := repository.NewRepository(db)
+	if err != nil {
+		fatal("failed to create repository", err)
+	}
+
+	consumer := consumer.NewConsumer(cfg.Kafka)
+	metrics.RegisterDB(db.DB)
+	orderCache, breaker, err := newOrderCache(cfg)
+	if err != nil {
+		fatal("failed to create cache", err)
+	}
+	service := service.NewService(ctx, repository, consumer, orderCache, cfg)
 
-	handler := handlers.NewHandler(service)
-	log.Println("handler created")
+	health := newHealth(cfg, db, breaker, consumer, service)
+	handler := handlers.NewHandler(service, health, cfg.Server.AdminToken)
 
-	if err := http.ListenAndServe(":"+os.Getenv("SERVER_PORT"), handler.InitRouts()); err != nil {
-		panic(err)
+	server := &http.Server{
+		Addr:    ":" + cfg.Server.Port,
+		Handler: handler.InitRouts(),
 	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		slog.Info("shutdown signal received")
+	case err := <-serverErr:
+		slog.Error("http server error", logger.Err(err))
+	}
+	stop()
+
+	// fail readiness first and give load balancers time to notice before connections are refused
+	health.SetShuttingDown()
+	if cfg.Server.ShutdownDelay > 0 {
+		slog.Info("waiting before shutdown", "delay", cfg.Server.ShutdownDelay.String())
+		time.Sleep(cfg.Server.ShutdownDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to shutdown http server", logger.Err(err))
+	}
+	if err := service.Shutdown(shutdownCtx); err != nil {
+		slog.Error("failed to shutdown service", logger.Err(err))
+	}
+	if err := orderCache.Close(); err != nil {
+		slog.Error("failed to close cache", logger.Err(err))
+	}
+	if err := db.Close(); err != nil {
+		slog.Error("failed to close db", logger.Err(err))
+	}
+	slog.Info("service stopped")
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, logger.Err(err))
+	os.Exit(1)
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/karambo3a/wbtech_test_task/internal/migrator"
+	"github.com/karambo3a/wbtech_test_task/migrations"
+)
+
+const usage = "usage: main [flags] [migrate up | migrate down [steps] | migrate status | seed]"
+
+func newMigrator(db *sqlx.DB) (*migrator.Migrator, error) {
+	return migrator.NewMigrator(db, migrations.FS, migrations.SeedFS)
+}
+
+// runCommand runs a maintenance command instead of the service.
+func runCommand(ctx context.Context, db *sqlx.DB, args []string) error {
+	m, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(args) == 1 && args[0] == "seed":
+		return m.Seed(ctx)
+	case len(args) == 2 && args[0] == "migrate" && args[1] == "up":
+		count, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d migrations applied\n", count)
+		return nil
+	case len(args) >= 2 && len(args) <= 3 && args[0] == "migrate" && args[1] == "down":
+		steps := 1
+		if len(args) == 3 {
+			if steps, err = strconv.Atoi(args[2]); err != nil || steps <= 0 {
+				return fmt.Errorf("steps must be a positive number\n%s", usage)
+			}
+		}
+		count, err := m.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d migrations rolled back\n", count)
+		return nil
+	case len(args) == 2 && args[0] == "migrate" && args[1] == "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied at " + status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown command\n%s", usage)
+	}
+}
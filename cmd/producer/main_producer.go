@@ -23,9 +23,11 @@ func main() {
 	for {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 
+		orderUID, order := generateOrder()
 		err = w.WriteMessages(ctx, kafka.Message{
-			Key:   []byte("order"),
-			Value: []byte(generateOrder()),
+			Key:     []byte(orderUID),
+			Value:   []byte(order),
+			Headers: []kafka.Header{{Key: "correlation-id", Value: []byte(orderUID)}},
 		})
 		cancel()
 
@@ -42,7 +44,7 @@ func main() {
 	}
 }
 
-func generateOrder() string {
+func generateOrder() (string, string) {
 	timestamp := time.Now().Unix()
 	randomNum := rand.Intn(10000)
 	orderUID := fmt.Sprintf("order_%d_%04d", timestamp, randomNum)
@@ -65,11 +67,11 @@ func generateOrder() string {
 			"request_id": "req_987654",
 			"currency": "RUB",
 			"provider": "sberpay",
-			"amount": 5420,
+			"amount": 2591,
 			"payment_dt": 1672534891,
 			"bank": "sberbank",
 			"delivery_cost": 500,
-			"goods_total": 4920,
+			"goods_total": 2091,
 			"custom_fee": 0
 		},
 		"items": [
@@ -97,5 +99,5 @@ func generateOrder() string {
 		"oof_shard": "2"
 	}`
 
-	return fmt.Sprintf(template, orderUID, orderUID)
+	return orderUID, fmt.Sprintf(template, orderUID, orderUID)
 }
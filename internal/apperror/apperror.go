@@ -0,0 +1,33 @@
+package apperror
+
+import "errors"
+
+// Domain errors shared by repository, cache and service.
+// Handlers map them to HTTP statuses: 404, 400, 503 and 409.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrUnavailable  = errors.New("unavailable")
+	ErrConflict     = errors.New("conflict")
+)
+
+// Error attaches a domain error kind to err while keeping its message.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func Wrap(kind error, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
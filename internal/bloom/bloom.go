@@ -0,0 +1,63 @@
+package bloom
+
+import (
+	"hash/fnv"
+	"math"
+	"sync/atomic"
+)
+
+// Filter is a Bloom filter of strings that is safe for concurrent use.
+// Test never reports an added string as absent, but may report a string that was not added as present.
+type Filter struct {
+	bits   []atomic.Uint64
+	m      uint64 // number of bits
+	hashes uint64
+}
+
+// NewFilter sizes the filter for capacity strings with the false positive rate fpRate.
+// Past the capacity the filter keeps working, only the false positive rate grows.
+func NewFilter(capacity uint64, fpRate float64) *Filter {
+	capacity = max(capacity, 1)
+	m := uint64(math.Ceil(-float64(capacity) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	m = max(m, 64)
+	hashes := uint64(math.Round(float64(m) / float64(capacity) * math.Ln2))
+	return &Filter{
+		bits:   make([]atomic.Uint64, (m+63)/64),
+		m:      m,
+		hashes: max(hashes, 1),
+	}
+}
+
+func (f *Filter) Add(s string) {
+	h1, h2 := hash(s)
+	for i := range f.hashes {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64].Or(1 << (bit % 64))
+	}
+}
+
+func (f *Filter) Test(s string) bool {
+	h1, h2 := hash(s)
+	for i := range f.hashes {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64].Load()&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SizeBytes is the memory taken by the bits of the filter.
+func (f *Filter) SizeBytes() int {
+	return len(f.bits) * 8
+}
+
+// hash returns two independent hashes of s for double hashing: the i-th bit is h1 + i*h2.
+func hash(s string) (uint64, uint64) {
+	a := fnv.New64a()
+	_, _ = a.Write([]byte(s))
+	b := fnv.New64()
+	_, _ = b.Write([]byte(s))
+	// a zero h2 would map all hashes of s to the same bit
+	return a.Sum64(), b.Sum64() | 1
+}
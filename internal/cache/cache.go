@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+)
+
+//go:generate mockgen -source=cache.go -destination=../../test/mocks/cache_mock.go
+
+// OrderCache stores orders by order_uid. A missing key is reported as apperror.ErrNotFound,
+// an unreachable backend as apperror.ErrUnavailable.
+type OrderCache interface {
+	// Get returns the order and the time it expires, zero if it never expires.
+	Get(ctx context.Context, key string) (*model.Order, time.Time, error)
+	// GetMany returns the cached orders by key, missing keys are left out.
+	GetMany(ctx context.Context, keys []string) (map[string]*model.Order, error)
+	Set(ctx context.Context, key string, value *model.Order, expiration time.Duration) error
+	// SetMany stores orders by their order_uid.
+	SetMany(ctx context.Context, orders []*model.Order, expiration time.Duration) error
+	// SetNotFound remembers that there is no order for key unless one is already cached.
+	// Get then returns ErrCachedNotFound until Set, Delete or expiration.
+	SetNotFound(ctx context.Context, key string, expiration time.Duration) error
+	Delete(ctx context.Context, key string) error
+	Stats() Stats
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+// Stats are counted since the cache was created. Entries and Bytes are only known for caches held in memory.
+type Stats struct {
+	Hits      int64
+	Misses    int64
+	Errors    int64
+	Evictions int64
+	Entries   int
+	Bytes     int64
+}
+
+// ErrCachedNotFound is returned by Get for keys cached as not found, together with apperror.ErrNotFound.
+var ErrCachedNotFound = errors.New("order is cached as not found")
+
+type counters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// record counts the result of a lookup, a key cached as not found is a hit.
+func (c *counters) record(err error) {
+	switch {
+	case err == nil || errors.Is(err, ErrCachedNotFound):
+		c.hits.Add(1)
+	case errors.Is(err, apperror.ErrNotFound):
+		c.misses.Add(1)
+	default:
+		c.errors.Add(1)
+	}
+}
+
+func (c *counters) stats() Stats {
+	return Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+}
+
+// orderSize approximates the memory an order takes by its encoded size.
+func orderSize(order *model.Order) (int64, error) {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(data)), nil
+}
@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+	"github.com/karambo3a/wbtech_test_task/internal/logger"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+)
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+type BreakerStats struct {
+	State               BreakerState
+	ConsecutiveFailures int
+	Rejected            int64
+	OpenedAt            time.Time
+}
+
+var errBreakerOpen = apperror.Wrap(apperror.ErrUnavailable, errors.New("cache circuit breaker is open"))
+
+// CircuitBreakerCache stops calling the underlying cache for cooldown after
+// failureThreshold consecutive unavailability errors. After the cooldown a single
+// probe request is let through: success closes the breaker, failure opens it again.
+type CircuitBreakerCache struct {
+	cache            OrderCache
+	failureThreshold int
+	cooldown         time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	rejected int64
+	openedAt time.Time
+}
+
+func NewCircuitBreakerCache(cache OrderCache, failureThreshold int, cooldown time.Duration) *CircuitBreakerCache {
+	return &CircuitBreakerCache{
+		cache:            cache,
+		failureThreshold: max(failureThreshold, 1),
+		cooldown:         cooldown,
+	}
+}
+
+func (b *CircuitBreakerCache) Get(ctx context.Context, key string) (*model.Order, time.Time, error) {
+	if !b.allow(ctx) {
+		return &model.Order{}, time.Time{}, errBreakerOpen
+	}
+	order, expiresAt, err := b.cache.Get(ctx, key)
+	b.record(ctx, err)
+	return order, expiresAt, err
+}
+
+func (b *CircuitBreakerCache) GetMany(ctx context.Context, keys []string) (map[string]*model.Order, error) {
+	if !b.allow(ctx) {
+		return nil, errBreakerOpen
+	}
+	orders, err := b.cache.GetMany(ctx, keys)
+	b.record(ctx, err)
+	return orders, err
+}
+
+func (b *CircuitBreakerCache) Set(ctx context.Context, key string, value *model.Order, expiration time.Duration) error {
+	if !b.allow(ctx) {
+		return errBreakerOpen
+	}
+	err := b.cache.Set(ctx, key, value, expiration)
+	b.record(ctx, err)
+	return err
+}
+
+func (b *CircuitBreakerCache) SetMany(ctx context.Context, orders []*model.Order, expiration time.Duration) error {
+	if !b.allow(ctx) {
+		return errBreakerOpen
+	}
+	err := b.cache.SetMany(ctx, orders, expiration)
+	b.record(ctx, err)
+	return err
+}
+
+func (b *CircuitBreakerCache) SetNotFound(ctx context.Context, key string, expiration time.Duration) error {
+	if !b.allow(ctx) {
+		return errBreakerOpen
+	}
+	err := b.cache.SetNotFound(ctx, key, expiration)
+	b.record(ctx, err)
+	return err
+}
+
+func (b *CircuitBreakerCache) Delete(ctx context.Context, key string) error {
+	if !b.allow(ctx) {
+		return errBreakerOpen
+	}
+	err := b.cache.Delete(ctx, key)
+	b.record(ctx, err)
+	return err
+}
+
+// Stats are the stats of the underlying cache, see BreakerStats for the breaker itself.
+func (b *CircuitBreakerCache) Stats() Stats {
+	return b.cache.Stats()
+}
+
+// Ping bypasses the breaker, so health checks see the actual state of the cache.
+func (b *CircuitBreakerCache) Ping(ctx context.Context) error {
+	return b.cache.Ping(ctx)
+}
+
+func (b *CircuitBreakerCache) Close() error {
+	return b.cache.Close()
+}
+
+func (b *CircuitBreakerCache) BreakerStats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return BreakerStats{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Rejected:            b.rejected,
+		OpenedAt:            b.openedAt,
+	}
+}
+
+func (b *CircuitBreakerCache) allow(ctx context.Context) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			b.rejected++
+			return false
+		}
+		b.state = BreakerHalfOpen
+		slog.InfoContext(ctx, "cache circuit breaker is half-open, probing")
+		return true
+	case BreakerHalfOpen:
+		// only the probe request is let through until it completes
+		b.rejected++
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *CircuitBreakerCache) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// a call cancelled by the caller says nothing about the cache. Deadlines are still counted:
+	// cache calls are bounded by a timeout, so a hanging cache fails with one.
+	if err != nil && (errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled)) {
+		if b.state == BreakerHalfOpen {
+			// the probe did not complete, the next call probes again
+			b.state = BreakerOpen
+		}
+		return
+	}
+
+	if !errors.Is(err, apperror.ErrUnavailable) {
+		if b.state != BreakerClosed {
+			slog.InfoContext(ctx, "cache circuit breaker is closed")
+		}
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.failureThreshold {
+		if b.state != BreakerOpen {
+			slog.WarnContext(ctx, "cache circuit breaker is open", "cooldown", b.cooldown.String(), "failures", b.failures, logger.Err(err))
+		}
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+)
+
+type lruEntry struct {
+	key   string
+	order *model.Order
+	// notFound marks a key cached as having no order
+	notFound bool
+	size     int64
+	// zero expiresAt never expires
+	expiresAt time.Time
+	// remoteExpiresAt is when the order expires in the remote cache
+	remoteExpiresAt time.Time
+}
+
+func (e *lruEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// lru keeps the most recently used orders within maxEntries and approximately maxBytes.
+type lru struct {
+	maxEntries int
+	maxBytes   int64
+
+	mu        sync.Mutex
+	entries   map[string]*list.Element
+	order     *list.List // front is the most recently used
+	bytes     int64
+	evictions int64
+}
+
+func newLRU(maxEntries int, maxBytes int64) *lru {
+	return &lru{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (c *lru) get(key string, now time.Time) (*lruEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if entry.expired(now) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return entry, true
+}
+
+// set stores the entry, replacing the previous one for its key, and returns how many entries were evicted.
+// An entry larger than maxBytes is not stored.
+func (c *lru) set(entry *lruEntry) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.setLocked(entry)
+}
+
+// setIfAbsent is set that keeps an unexpired entry for the same key.
+func (c *lru) setIfAbsent(entry *lruEntry, now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[entry.key]; ok && !elem.Value.(*lruEntry).expired(now) {
+		return 0
+	}
+	return c.setLocked(entry)
+}
+
+func (c *lru) setLocked(entry *lruEntry) int {
+	if elem, ok := c.entries[entry.key]; ok {
+		c.remove(elem)
+	}
+	if c.maxBytes > 0 && entry.size > c.maxBytes {
+		return 0
+	}
+
+	c.entries[entry.key] = c.order.PushFront(entry)
+	c.bytes += entry.size
+
+	evicted := 0
+	for len(c.entries) > c.maxEntries || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.remove(c.order.Back())
+		evicted++
+	}
+	c.evictions += int64(evicted)
+	return evicted
+}
+
+func (c *lru) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+}
+
+func (c *lru) stats() (entries int, bytes int64, evictions int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries), c.bytes, c.evictions
+}
+
+func (c *lru) remove(elem *list.Element) {
+	entry := c.order.Remove(elem).(*lruEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+}
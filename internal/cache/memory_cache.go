@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+	"github.com/karambo3a/wbtech_test_task/internal/metrics"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+)
+
+// MemoryCache keeps orders in process memory with expiration and LRU eviction,
+// for running without Redis. Returned orders are shared between callers and must not be modified.
+type MemoryCache struct {
+	entries *lru
+	counters
+}
+
+func NewMemoryCache(maxEntries int, maxBytes int64) *MemoryCache {
+	return &MemoryCache{entries: newLRU(maxEntries, maxBytes)}
+}
+
+func (c *MemoryCache) Get(_ context.Context, key string) (*model.Order, time.Time, error) {
+	order, expiresAt, err := c.get(key)
+	c.record(err)
+	return order, expiresAt, err
+}
+
+func (c *MemoryCache) get(key string) (*model.Order, time.Time, error) {
+	entry, ok := c.entries.get(key, time.Now())
+	if !ok {
+		metrics.CacheRequests.WithLabelValues(metrics.TierMemory, metrics.CacheMiss).Inc()
+		return &model.Order{}, time.Time{}, apperror.Wrap(apperror.ErrNotFound, fmt.Errorf("cache miss for key=%s", key))
+	}
+	metrics.CacheRequests.WithLabelValues(metrics.TierMemory, metrics.CacheHit).Inc()
+	if entry.notFound {
+		return &model.Order{}, time.Time{}, apperror.Wrap(apperror.ErrNotFound, fmt.Errorf("key=%s: %w", key, ErrCachedNotFound))
+	}
+	return entry.order, entry.expiresAt, nil
+}
+
+func (c *MemoryCache) GetMany(_ context.Context, keys []string) (map[string]*model.Order, error) {
+	orders := make(map[string]*model.Order, len(keys))
+	for _, key := range keys {
+		order, _, err := c.get(key)
+		c.record(err)
+		if err == nil {
+			orders[key] = order
+		}
+	}
+	return orders, nil
+}
+
+func (c *MemoryCache) Set(_ context.Context, key string, value *model.Order, expiration time.Duration) error {
+	size, err := orderSize(value)
+	if err != nil {
+		return fmt.Errorf("failed to create json: %w", err)
+	}
+	evicted := c.entries.set(&lruEntry{key: key, order: value, size: size, expiresAt: expiresAt(expiration)})
+	metrics.CacheEvictions.WithLabelValues(metrics.TierMemory).Add(float64(evicted))
+	return nil
+}
+
+func (c *MemoryCache) SetMany(ctx context.Context, orders []*model.Order, expiration time.Duration) error {
+	for _, order := range orders {
+		if err := c.Set(ctx, order.OrderUID, order, expiration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *MemoryCache) SetNotFound(_ context.Context, key string, expiration time.Duration) error {
+	evicted := c.entries.setIfAbsent(&lruEntry{key: key, notFound: true, expiresAt: expiresAt(expiration)}, time.Now())
+	metrics.CacheEvictions.WithLabelValues(metrics.TierMemory).Add(float64(evicted))
+	return nil
+}
+
+func (c *MemoryCache) Delete(_ context.Context, key string) error {
+	c.entries.delete(key)
+	return nil
+}
+
+func (c *MemoryCache) Stats() Stats {
+	stats := c.stats()
+	stats.Entries, stats.Bytes, stats.Evictions = c.entries.stats()
+	return stats
+}
+
+func (c *MemoryCache) Ping(context.Context) error {
+	return nil
+}
+
+func (c *MemoryCache) Close() error {
+	return nil
+}
+
+// expiresAt returns the zero time, which never expires, for a non-positive expiration as Redis does.
+func expiresAt(expiration time.Duration) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(expiration)
+}
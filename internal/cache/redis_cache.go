@@ -2,73 +2,163 @@ package cache
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"time"
 
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+	"github.com/karambo3a/wbtech_test_task/internal/config"
+	"github.com/karambo3a/wbtech_test_task/internal/logger"
+	"github.com/karambo3a/wbtech_test_task/internal/metrics"
 	"github.com/karambo3a/wbtech_test_task/internal/model"
 	"github.com/redis/go-redis/v9"
 )
 
-//go:generate mockgen -source=redis_cache.go -destination=../../test/mocks/redis_cache_mock.go
-
-type RedisCache interface {
-	Init(orders []*model.Order) error
-	Get(ctx context.Context, key string) (*model.Order, error)
-	Set(ctx context.Context, key string, value *model.Order, expiration time.Duration) error
-}
+// notFoundValue marks a key without an order, an encoded order is never empty
+var notFoundValue = []byte{}
 
+// RedisCacheImpl stores orders under <prefix>:v<model.OrderSchemaVersion>:<order_uid>,
+// keys are given and returned as plain order UIDs.
 type RedisCacheImpl struct {
-	client  *redis.Client
-	maxSize int64
+	client    *redis.Client
+	keyPrefix string
+	counters
 }
 
-func NewRedisCache(maxMemoryMB int) *RedisCacheImpl {
-	client := redis.NewClient(&redis.Options{
-		Addr:     os.Getenv("REDIS"),
-		Password: "",
-		DB:       0,
-	})
+func NewRedisCache(cfg config.RedisConfig) (*RedisCacheImpl, error) {
+	options := &redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	}
+	if cfg.TLS {
+		tlsConfig, err := newTLSConfig(cfg.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		options.TLSConfig = tlsConfig
+	}
+	client := redis.NewClient(options)
 
-	ctx := context.Background()
-	maxMemory := fmt.Sprintf("%dmb", maxMemoryMB)
-	client.ConfigSet(ctx, "maxmemory", maxMemory)
-	client.ConfigSet(ctx, "maxmemory-policy", "allkeys-lru")
+	if cfg.ConfigureServer {
+		configureServer(client, cfg.MaxMemoryMB)
+	}
 
 	return &RedisCacheImpl{
-		client:  client,
-		maxSize: int64(maxMemoryMB * 1024 * 1024),
+		client:    client,
+		keyPrefix: newKeyPrefix(cfg.KeyPrefix, model.OrderSchemaVersion),
+	}, nil
+}
+
+func newKeyPrefix(prefix string, schemaVersion int) string {
+	return fmt.Sprintf("%s:v%d:", prefix, schemaVersion)
+}
+
+func newTLSConfig(caFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redis ca file: %w", err)
 	}
+	tlsConfig.RootCAs = x509.NewCertPool()
+	if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("failed to parse redis ca file %s: no certificates found", caFile)
+	}
+	return tlsConfig, nil
 }
 
-func (rc *RedisCacheImpl) Init(orders []*model.Order) error {
-	for _, order := range orders {
-		if err := rc.Set(context.TODO(), order.OrderUID, order, 24*time.Hour); err != nil {
-			continue
-		}
+// configureServer limits the memory of the whole Redis server and makes it evict least recently used keys.
+// Failures are only logged, Redis may be unavailable at startup and the service works without it.
+func configureServer(client *redis.Client, maxMemoryMB int) {
+	ctx := context.Background()
+	maxMemory := fmt.Sprintf("%dmb", maxMemoryMB)
+	if err := client.ConfigSet(ctx, "maxmemory", maxMemory).Err(); err != nil {
+		slog.Warn("failed to set redis maxmemory", "maxmemory", maxMemory, logger.Err(err))
+	}
+	if err := client.ConfigSet(ctx, "maxmemory-policy", "allkeys-lru").Err(); err != nil {
+		slog.Warn("failed to set redis maxmemory-policy", logger.Err(err))
 	}
+}
 
-	log.Println("cache initialized")
-	return nil
+func (rc *RedisCacheImpl) key(orderUID string) string {
+	return rc.keyPrefix + orderUID
+}
+
+func (rc *RedisCacheImpl) Get(ctx context.Context, key string) (*model.Order, time.Time, error) {
+	order, expiresAt, err := rc.get(ctx, key)
+	rc.record(err)
+	return order, expiresAt, err
 }
 
-func (rc *RedisCacheImpl) Get(ctx context.Context, key string) (*model.Order, error) {
-	bytes, err := rc.client.Get(ctx, key).Bytes()
-	if err == redis.Nil {
-		return &model.Order{}, fmt.Errorf("cache miss for key=%s: %w", key, err)
+func (rc *RedisCacheImpl) get(ctx context.Context, key string) (*model.Order, time.Time, error) {
+	// the TTL comes in the same round trip, so the caller can refresh the key before it expires
+	pipe := rc.client.Pipeline()
+	get := pipe.Get(ctx, rc.key(key))
+	ttl := pipe.PTTL(ctx, rc.key(key))
+	_, err := pipe.Exec(ctx)
+	if errors.Is(err, redis.Nil) {
+		metrics.CacheRequests.WithLabelValues(metrics.TierRedis, metrics.CacheMiss).Inc()
+		return &model.Order{}, time.Time{}, apperror.Wrap(apperror.ErrNotFound, fmt.Errorf("cache miss for key=%s", key))
 	} else if err != nil {
-		return &model.Order{}, fmt.Errorf("failed to get value by key=%s: %w", key, err)
+		metrics.CacheRequests.WithLabelValues(metrics.TierRedis, metrics.CacheError).Inc()
+		return &model.Order{}, time.Time{}, apperror.Wrap(apperror.ErrUnavailable, fmt.Errorf("failed to get value by key=%s: %w", key, err))
 	}
 
-	var order model.Order
-	if err = json.Unmarshal(bytes, &order); err != nil {
-		return &model.Order{}, fmt.Errorf("failed to parse json: %w", err)
+	order, err := decodeOrder(key, get.Val())
+	if err != nil {
+		return &model.Order{}, time.Time{}, err
 	}
 
-	log.Printf("got order_uid=%s from cache\n", key)
-	return &order, nil
+	var expiresAt time.Time
+	if d := ttl.Val(); d > 0 {
+		expiresAt = time.Now().Add(d)
+	}
+
+	slog.DebugContext(ctx, "got order from cache", "order_uid", key)
+	return order, expiresAt, nil
+}
+
+func (rc *RedisCacheImpl) GetMany(ctx context.Context, keys []string) (map[string]*model.Order, error) {
+	orders := make(map[string]*model.Order, len(keys))
+	if len(keys) == 0 {
+		return orders, nil
+	}
+
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = rc.key(key)
+	}
+	values, err := rc.client.MGet(ctx, redisKeys...).Result()
+	if err != nil {
+		rc.errors.Add(int64(len(keys)))
+		metrics.CacheRequests.WithLabelValues(metrics.TierRedis, metrics.CacheError).Add(float64(len(keys)))
+		return nil, apperror.Wrap(apperror.ErrUnavailable, fmt.Errorf("failed to get values: %w", err))
+	}
+
+	for i, value := range values {
+		// missing keys come back as nil
+		raw, ok := value.(string)
+		if !ok {
+			rc.misses.Add(1)
+			metrics.CacheRequests.WithLabelValues(metrics.TierRedis, metrics.CacheMiss).Inc()
+			continue
+		}
+		order, err := decodeOrder(keys[i], raw)
+		rc.record(err)
+		if err == nil {
+			orders[keys[i]] = order
+		}
+	}
+	return orders, nil
 }
 
 func (rc *RedisCacheImpl) Set(ctx context.Context, key string, value *model.Order, expiration time.Duration) error {
@@ -76,10 +166,83 @@ func (rc *RedisCacheImpl) Set(ctx context.Context, key string, value *model.Orde
 	if err != nil {
 		return fmt.Errorf("failed to create json: %w", err)
 	}
-	if err = rc.client.Set(ctx, key, bytes, expiration).Err(); err != nil {
-		return fmt.Errorf("failed to set data: %w", err)
+	if err = rc.client.Set(ctx, rc.key(key), bytes, expiration).Err(); err != nil {
+		return apperror.Wrap(apperror.ErrUnavailable, fmt.Errorf("failed to set data: %w", err))
+	}
+
+	slog.DebugContext(ctx, "set order in cache", "order_uid", key)
+	return nil
+}
+
+// SetMany writes all orders in one round trip.
+func (rc *RedisCacheImpl) SetMany(ctx context.Context, orders []*model.Order, expiration time.Duration) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	pipe := rc.client.Pipeline()
+	for _, order := range orders {
+		bytes, err := json.Marshal(order)
+		if err != nil {
+			return fmt.Errorf("failed to create json for order_uid=%s: %w", order.OrderUID, err)
+		}
+		pipe.Set(ctx, rc.key(order.OrderUID), bytes, expiration)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return apperror.Wrap(apperror.ErrUnavailable, fmt.Errorf("failed to set data: %w", err))
+	}
+
+	slog.DebugContext(ctx, "set orders in cache", "orders", len(orders))
+	return nil
+}
+
+func (rc *RedisCacheImpl) SetNotFound(ctx context.Context, key string, expiration time.Duration) error {
+	// NX keeps an order that was cached in the meantime
+	if err := rc.client.SetNX(ctx, rc.key(key), notFoundValue, expiration).Err(); err != nil {
+		return apperror.Wrap(apperror.ErrUnavailable, fmt.Errorf("failed to set data: %w", err))
 	}
 
-	log.Printf("set order_uid=%s in cache\n", key)
+	slog.DebugContext(ctx, "set order as not found in cache", "order_uid", key)
 	return nil
 }
+
+func (rc *RedisCacheImpl) Delete(ctx context.Context, key string) error {
+	if err := rc.client.Del(ctx, rc.key(key)).Err(); err != nil {
+		return apperror.Wrap(apperror.ErrUnavailable, fmt.Errorf("failed to delete key=%s: %w", key, err))
+	}
+	return nil
+}
+
+// Stats counts the lookups of this client only, the size of the Redis database is not tracked.
+func (rc *RedisCacheImpl) Stats() Stats {
+	return rc.stats()
+}
+
+func (rc *RedisCacheImpl) Ping(ctx context.Context) error {
+	if err := rc.client.Ping(ctx).Err(); err != nil {
+		return apperror.Wrap(apperror.ErrUnavailable, fmt.Errorf("failed to ping redis: %w", err))
+	}
+	return nil
+}
+
+func (rc *RedisCacheImpl) Close() error {
+	if err := rc.client.Close(); err != nil {
+		return fmt.Errorf("failed to close redis client: %w", err)
+	}
+	return nil
+}
+
+// decodeOrder parses a cached value, the not found marker becomes ErrCachedNotFound. It also counts the lookup.
+func decodeOrder(key string, raw string) (*model.Order, error) {
+	if raw == string(notFoundValue) {
+		metrics.CacheRequests.WithLabelValues(metrics.TierRedis, metrics.CacheHit).Inc()
+		return nil, apperror.Wrap(apperror.ErrNotFound, fmt.Errorf("key=%s: %w", key, ErrCachedNotFound))
+	}
+	var order model.Order
+	if err := json.Unmarshal([]byte(raw), &order); err != nil {
+		metrics.CacheRequests.WithLabelValues(metrics.TierRedis, metrics.CacheError).Inc()
+		return nil, fmt.Errorf("failed to parse json: %w", err)
+	}
+	metrics.CacheRequests.WithLabelValues(metrics.TierRedis, metrics.CacheHit).Inc()
+	return &order, nil
+}
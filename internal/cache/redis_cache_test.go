@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+	"github.com/karambo3a/wbtech_test_task/internal/config"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisCacheKeys(t *testing.T) {
+	server := miniredis.RunT(t)
+	rc, err := NewRedisCache(config.RedisConfig{Addr: server.Addr(), KeyPrefix: "orders"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	ctx := context.Background()
+
+	order := &model.Order{OrderUID: "order_uid1", TrackNumber: "WBILMTESTTRACK"}
+	if err := rc.Set(ctx, "order_uid1", order, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := rc.SetNotFound(ctx, "order_uid2", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	version := model.OrderSchemaVersion
+	assert.Equal(t, []string{fmt.Sprintf("orders:v%d:order_uid1", version), fmt.Sprintf("orders:v%d:order_uid2", version)}, server.Keys())
+
+	got, _, err := rc.Get(ctx, "order_uid1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, order.TrackNumber, got.TrackNumber)
+
+	// after a schema version bump the orders cached in the old format are not read
+	bumped := &RedisCacheImpl{client: rc.client, keyPrefix: newKeyPrefix("orders", version+1)}
+	_, _, err = bumped.Get(ctx, "order_uid1")
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+	orders, err := bumped.GetMany(ctx, []string{"order_uid1", "order_uid2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, orders)
+
+	if err := bumped.Set(ctx, "order_uid1", order, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, server.Keys(), fmt.Sprintf("orders:v%d:order_uid1", version+1))
+	assert.Contains(t, server.Keys(), fmt.Sprintf("orders:v%d:order_uid1", version), "old keys are left to expire")
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, newTestCA(t), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	invalidFile := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalidFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		caFile      string
+		withRootCAs bool
+		expectedErr string
+	}{
+		{name: "system roots", caFile: ""},
+		{name: "ca file", caFile: caFile, withRootCAs: true},
+		{name: "missing file", caFile: filepath.Join(dir, "missing.pem"), expectedErr: "failed to read redis ca file"},
+		{name: "no certificates", caFile: invalidFile, expectedErr: "no certificates found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := newTLSConfig(tt.caFile)
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+			assert.Equal(t, tt.withRootCAs, tlsConfig.RootCAs != nil)
+		})
+	}
+}
+
+func newTestCA(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
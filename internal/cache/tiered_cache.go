@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/karambo3a/wbtech_test_task/internal/metrics"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+)
+
+// TieredCache serves hot orders from process memory and falls back to the remote cache.
+// The local tier has its own, usually shorter, TTL: other instances do not invalidate it,
+// so the TTL bounds how long a replaced order may be served from memory.
+// Returned orders are shared between callers and must not be modified.
+type TieredCache struct {
+	remote OrderCache
+	local  *lru
+	ttl    time.Duration
+
+	localHits atomic.Int64
+	// remoteResults counts the lookups that reached the remote cache
+	remoteResults counters
+}
+
+func NewTieredCache(remote OrderCache, maxEntries int, maxBytes int64, ttl time.Duration) *TieredCache {
+	return &TieredCache{
+		remote: remote,
+		local:  newLRU(maxEntries, maxBytes),
+		ttl:    ttl,
+	}
+}
+
+// Get returns the expiration time of the order in the remote cache, also when the order is served from memory.
+func (c *TieredCache) Get(ctx context.Context, key string) (*model.Order, time.Time, error) {
+	if order, expiresAt, ok := c.getLocal(key); ok {
+		return order, expiresAt, nil
+	}
+
+	order, expiresAt, err := c.remote.Get(ctx, key)
+	c.remoteResults.record(err)
+	if err != nil {
+		return order, expiresAt, err
+	}
+	c.setLocal(key, order, c.ttl, expiresAt)
+	return order, expiresAt, nil
+}
+
+func (c *TieredCache) GetMany(ctx context.Context, keys []string) (map[string]*model.Order, error) {
+	orders := make(map[string]*model.Order, len(keys))
+	var missing []string
+	for _, key := range keys {
+		if order, _, ok := c.getLocal(key); ok {
+			orders[key] = order
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return orders, nil
+	}
+
+	found, err := c.remote.GetMany(ctx, missing)
+	if err != nil {
+		c.remoteResults.errors.Add(int64(len(missing)))
+		return orders, err
+	}
+	for _, key := range missing {
+		order, ok := found[key]
+		if !ok {
+			c.remoteResults.misses.Add(1)
+			continue
+		}
+		c.remoteResults.hits.Add(1)
+		// the remote expiration is not known, so the order is not refreshed early while in memory
+		c.setLocal(key, order, c.ttl, time.Time{})
+		orders[key] = order
+	}
+	return orders, nil
+}
+
+// Set writes the order through to the remote cache and replaces the local copy,
+// so this instance never serves the previous version.
+func (c *TieredCache) Set(ctx context.Context, key string, value *model.Order, expiration time.Duration) error {
+	c.setLocal(key, value, min(c.ttl, expiration), expiresAt(expiration))
+	return c.remote.Set(ctx, key, value, expiration)
+}
+
+// SetMany fills only the remote cache, the local tier is filled by the orders actually requested.
+// Local copies of the orders are dropped, so their previous versions are not served.
+func (c *TieredCache) SetMany(ctx context.Context, orders []*model.Order, expiration time.Duration) error {
+	for _, order := range orders {
+		c.local.delete(order.OrderUID)
+	}
+	return c.remote.SetMany(ctx, orders, expiration)
+}
+
+// SetNotFound is only written to the remote cache, the local tier keeps just orders.
+func (c *TieredCache) SetNotFound(ctx context.Context, key string, expiration time.Duration) error {
+	c.local.delete(key)
+	return c.remote.SetNotFound(ctx, key, expiration)
+}
+
+func (c *TieredCache) Delete(ctx context.Context, key string) error {
+	c.local.delete(key)
+	return c.remote.Delete(ctx, key)
+}
+
+// Stats counts hits of both tiers, misses and errors of the remote one and the size of the local one.
+func (c *TieredCache) Stats() Stats {
+	stats := c.remoteResults.stats()
+	stats.Hits += c.localHits.Load()
+	stats.Entries, stats.Bytes, stats.Evictions = c.local.stats()
+	return stats
+}
+
+func (c *TieredCache) Ping(ctx context.Context) error {
+	return c.remote.Ping(ctx)
+}
+
+func (c *TieredCache) Close() error {
+	return c.remote.Close()
+}
+
+func (c *TieredCache) getLocal(key string) (*model.Order, time.Time, bool) {
+	entry, ok := c.local.get(key, time.Now())
+	if !ok {
+		metrics.CacheRequests.WithLabelValues(metrics.TierMemory, metrics.CacheMiss).Inc()
+		return nil, time.Time{}, false
+	}
+	c.localHits.Add(1)
+	metrics.CacheRequests.WithLabelValues(metrics.TierMemory, metrics.CacheHit).Inc()
+	return entry.order, entry.remoteExpiresAt, true
+}
+
+func (c *TieredCache) setLocal(key string, order *model.Order, ttl time.Duration, remoteExpiresAt time.Time) {
+	size, err := orderSize(order)
+	if err != nil {
+		c.local.delete(key)
+		return
+	}
+	evicted := c.local.set(&lruEntry{
+		key:             key,
+		order:           order,
+		size:            size,
+		expiresAt:       time.Now().Add(ttl),
+		remoteExpiresAt: remoteExpiresAt,
+	})
+	metrics.CacheEvictions.WithLabelValues(metrics.TierMemory).Add(float64(evicted))
+}
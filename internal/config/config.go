@@ -0,0 +1,283 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Config holds all service settings. Values are taken, from lowest to highest priority,
+// from defaults, the config file, environment variables and command-line flags.
+// The yaml tag is the key in the config file and, joined with the section name,
+// the flag name (e.g. -kafka.workers); env is the environment variable.
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	Kafka    KafkaConfig    `yaml:"kafka"`
+	Redis    RedisConfig    `yaml:"redis"`
+	Cache    CacheConfig    `yaml:"cache"`
+	Log      LogConfig      `yaml:"log"`
+}
+
+type ServerConfig struct {
+	Port            string        `yaml:"port" env:"SERVER_PORT"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	ShutdownDelay   time.Duration `yaml:"shutdown_delay" env:"SHUTDOWN_DELAY"`
+	HealthTimeout   time.Duration `yaml:"health_timeout" env:"HEALTH_TIMEOUT"`
+	AdminToken      string        `yaml:"admin_token" env:"ADMIN_TOKEN" secret:"true"`
+}
+
+type DatabaseConfig struct {
+	Host           string        `yaml:"host" env:"DATABASE_HOST"`
+	Port           string        `yaml:"port" env:"DATABASE_PORT"`
+	User           string        `yaml:"user" env:"DATABASE_USER"`
+	Password       string        `yaml:"password" env:"DATABASE_PASSWORD" secret:"true"`
+	Name           string        `yaml:"name" env:"DATABASE_NAME"`
+	SSLMode        string        `yaml:"sslmode" env:"DATABASE_SSLMODE"`
+	QueryTimeout   time.Duration `yaml:"query_timeout" env:"DATABASE_QUERY_TIMEOUT"`
+	MigrateOnStart bool          `yaml:"migrate_on_start" env:"MIGRATE_ON_START"`
+}
+
+type KafkaConfig struct {
+	Brokers        []string      `yaml:"brokers" env:"KAFKA_BROKERS_CONS"`
+	Topic          string        `yaml:"topic" env:"KAFKA_TOPIC"`
+	GroupID        string        `yaml:"group_id" env:"KAFKA_GROUP_ID"`
+	DLQTopic       string        `yaml:"dlq_topic" env:"KAFKA_DLQ_TOPIC"`
+	Workers        int           `yaml:"workers" env:"KAFKA_CONSUMER_WORKERS"`
+	BatchSize      int           `yaml:"batch_size" env:"KAFKA_BATCH_SIZE"`
+	BatchTimeout   time.Duration `yaml:"batch_timeout" env:"KAFKA_BATCH_TIMEOUT_MS" unit:"ms"`
+	ProcessTimeout time.Duration `yaml:"process_timeout" env:"KAFKA_PROCESS_TIMEOUT_MS" unit:"ms"`
+	MaxLag         int64         `yaml:"max_lag" env:"KAFKA_MAX_LAG"`
+}
+
+type RedisConfig struct {
+	Addr     string `yaml:"addr" env:"REDIS"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD" secret:"true"`
+	DB       int    `yaml:"db" env:"REDIS_DB"`
+	// TLS verifies the server against the system roots or, if set, the certificates in TLSCAFile
+	TLS       bool   `yaml:"tls" env:"REDIS_TLS"`
+	TLSCAFile string `yaml:"tls_ca_file" env:"REDIS_TLS_CA_FILE"`
+	// KeyPrefix namespaces cache keys as <prefix>:v<schema version>:<order_uid>
+	KeyPrefix string `yaml:"key_prefix" env:"REDIS_KEY_PREFIX"`
+	// ConfigureServer sets maxmemory to MaxMemoryMB and the allkeys-lru policy with CONFIG SET,
+	// which changes the whole server, so it is meant only for an instance dedicated to the service
+	ConfigureServer bool `yaml:"configure_server" env:"REDIS_CONFIGURE_SERVER"`
+	MaxMemoryMB     int  `yaml:"max_memory_mb" env:"REDIS_MAX_MEMORY_MB"`
+}
+
+type CacheConfig struct {
+	// Backend is redis or memory, the latter keeps orders only in process memory within the local limits
+	Backend         string        `yaml:"backend" env:"CACHE_BACKEND"`
+	TTL             time.Duration `yaml:"ttl" env:"CACHE_TTL"`
+	Timeout         time.Duration `yaml:"timeout" env:"CACHE_TIMEOUT"`
+	WarmupLimit     int64         `yaml:"warmup_limit" env:"CACHE_WARMUP_LIMIT"`
+	BreakerFailures int           `yaml:"breaker_failures" env:"CACHE_BREAKER_FAILURES"`
+	BreakerCooldown time.Duration `yaml:"breaker_cooldown" env:"CACHE_BREAKER_COOLDOWN"`
+	// WarmupStrategy is recent or frequent, the latter warms up the most read orders first
+	WarmupStrategy    string `yaml:"warmup_strategy" env:"CACHE_WARMUP_STRATEGY"`
+	WarmupPageSize    int    `yaml:"warmup_page_size" env:"CACHE_WARMUP_PAGE_SIZE"`
+	WarmupConcurrency int    `yaml:"warmup_concurrency" env:"CACHE_WARMUP_CONCURRENCY"`
+	// order reads are counted for the frequent strategy and saved every AccessFlushInterval
+	AccessFlushInterval time.Duration `yaml:"access_flush_interval" env:"CACHE_ACCESS_FLUSH_INTERVAL"`
+	// orders read within EarlyRefreshWindow of their expiration may be reloaded early, 0 disables it
+	EarlyRefreshWindow time.Duration `yaml:"early_refresh_window" env:"CACHE_EARLY_REFRESH_WINDOW"`
+	// unknown order UIDs are cached as not found for NegativeTTL, 0 disables it
+	NegativeTTL time.Duration `yaml:"negative_ttl" env:"CACHE_NEGATIVE_TTL"`
+	// the Bloom filter of stored order UIDs that rejects unknown ones without reading storage
+	BloomEnabled  bool    `yaml:"bloom_enabled" env:"CACHE_BLOOM_ENABLED"`
+	BloomCapacity int64   `yaml:"bloom_capacity" env:"CACHE_BLOOM_CAPACITY"`
+	BloomFPRate   float64 `yaml:"bloom_fp_rate" env:"CACHE_BLOOM_FP_RATE"`
+	// the filter is rebuilt from storage every BloomRefreshInterval, each scan is bounded by BloomLoadTimeout
+	BloomRefreshInterval time.Duration `yaml:"bloom_refresh_interval" env:"CACHE_BLOOM_REFRESH_INTERVAL"`
+	BloomLoadTimeout     time.Duration `yaml:"bloom_load_timeout" env:"CACHE_BLOOM_LOAD_TIMEOUT"`
+	// the in-memory tier in front of Redis, disabled with LocalMaxEntries = 0
+	LocalMaxEntries int           `yaml:"local_max_entries" env:"CACHE_LOCAL_MAX_ENTRIES"`
+	LocalMaxMB      int           `yaml:"local_max_mb" env:"CACHE_LOCAL_MAX_MB"`
+	LocalTTL        time.Duration `yaml:"local_ttl" env:"CACHE_LOCAL_TTL"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            "8081",
+			ShutdownTimeout: 15 * time.Second,
+			HealthTimeout:   2 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Port:           "5432",
+			SSLMode:        "disable",
+			QueryTimeout:   5 * time.Second,
+			MigrateOnStart: true,
+		},
+		Kafka: KafkaConfig{
+			Topic:          "order",
+			GroupID:        "order-service-group",
+			Workers:        4,
+			BatchTimeout:   500 * time.Millisecond,
+			ProcessTimeout: 10 * time.Second,
+		},
+		Redis: RedisConfig{
+			KeyPrefix:   "orders",
+			MaxMemoryMB: 50,
+		},
+		Cache: CacheConfig{
+			Backend:              "redis",
+			TTL:                  24 * time.Hour,
+			Timeout:              500 * time.Millisecond,
+			WarmupLimit:          100,
+			WarmupStrategy:       "recent",
+			WarmupPageSize:       500,
+			WarmupConcurrency:    4,
+			AccessFlushInterval:  time.Minute,
+			BreakerFailures:      5,
+			BreakerCooldown:      30 * time.Second,
+			EarlyRefreshWindow:   10 * time.Minute,
+			NegativeTTL:          time.Minute,
+			BloomCapacity:        1000000,
+			BloomFPRate:          0.01,
+			BloomRefreshInterval: 5 * time.Minute,
+			BloomLoadTimeout:     time.Minute,
+			LocalMaxEntries:      10000,
+			LocalMaxMB:           64,
+			LocalTTL:             time.Minute,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+	}
+}
+
+var (
+	sslModes         = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	cacheBackends    = []string{"redis", "memory"}
+	warmupStrategies = []string{"recent", "frequent"}
+	logLevels        = []string{"debug", "info", "warn", "error"}
+	logFormats       = []string{"json", "text"}
+)
+
+// Validate checks every section used by the service and returns all problems found at once.
+func (c *Config) Validate() error {
+	var v validation
+	// in-flight messages are finished within the shutdown budget, so their offsets are committed before exit
+	v.require(c.Kafka.ProcessTimeout < c.Server.ShutdownTimeout, "kafka.process_timeout must be shorter than server.shutdown_timeout")
+	v.require(c.Redis.Addr != "" || c.Cache.Backend == "memory", "redis.addr is required")
+
+	err := errors.Join(
+		c.Server.Validate(),
+		c.Database.Validate(),
+		c.Kafka.Validate(),
+		c.Redis.Validate(),
+		c.Cache.Validate(),
+		c.Log.Validate(),
+		v.err(),
+	)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
+
+// ValidateCommand checks only the sections used by the maintenance commands (migrate, seed):
+// the database and logging.
+func (c *Config) ValidateCommand() error {
+	if err := errors.Join(c.Database.Validate(), c.Log.Validate()); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
+
+// validation collects the problems found in a config section.
+type validation []error
+
+func (v *validation) require(ok bool, format string, args ...any) {
+	if !ok {
+		*v = append(*v, fmt.Errorf(format, args...))
+	}
+}
+
+func (v validation) err() error {
+	return errors.Join(v...)
+}
+
+func (c ServerConfig) Validate() error {
+	var v validation
+	v.require(c.Port != "", "server.port is required")
+	v.require(c.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	v.require(c.ShutdownDelay >= 0, "server.shutdown_delay must not be negative")
+	v.require(c.HealthTimeout > 0, "server.health_timeout must be positive")
+	return v.err()
+}
+
+func (c DatabaseConfig) Validate() error {
+	var v validation
+	v.require(c.Host != "", "database.host is required")
+	v.require(c.Port != "", "database.port is required")
+	v.require(c.User != "", "database.user is required")
+	v.require(c.Name != "", "database.name is required")
+	v.require(slices.Contains(sslModes, c.SSLMode), "database.sslmode must be one of %s", strings.Join(sslModes, ", "))
+	v.require(c.QueryTimeout > 0, "database.query_timeout must be positive")
+	return v.err()
+}
+
+func (c KafkaConfig) Validate() error {
+	var v validation
+	v.require(len(c.Brokers) > 0, "kafka.brokers is required")
+	v.require(c.Topic != "", "kafka.topic is required")
+	v.require(c.GroupID != "", "kafka.group_id is required")
+	v.require(c.Workers > 0, "kafka.workers must be positive")
+	v.require(c.BatchSize >= 0, "kafka.batch_size must not be negative")
+	v.require(c.BatchTimeout > 0, "kafka.batch_timeout must be positive")
+	v.require(c.ProcessTimeout >= 0, "kafka.process_timeout must not be negative")
+	v.require(c.MaxLag >= 0, "kafka.max_lag must not be negative")
+	return v.err()
+}
+
+// Validate checks the connection settings, whether Redis is needed at all depends on cache.backend.
+func (c RedisConfig) Validate() error {
+	var v validation
+	v.require(c.DB >= 0, "redis.db must not be negative")
+	v.require(c.TLSCAFile == "" || c.TLS, "redis.tls_ca_file requires redis.tls")
+	v.require(c.KeyPrefix != "", "redis.key_prefix is required")
+	v.require(c.MaxMemoryMB >= 0, "redis.max_memory_mb must not be negative")
+	return v.err()
+}
+
+func (c CacheConfig) Validate() error {
+	var v validation
+	v.require(slices.Contains(cacheBackends, c.Backend), "cache.backend must be one of %s", strings.Join(cacheBackends, ", "))
+	v.require(c.LocalMaxEntries > 0 || c.Backend != "memory", "cache.local_max_entries must be positive for the memory backend")
+	v.require(c.TTL > 0, "cache.ttl must be positive")
+	v.require(c.Timeout > 0, "cache.timeout must be positive")
+	v.require(c.WarmupLimit >= 0, "cache.warmup_limit must not be negative")
+	v.require(slices.Contains(warmupStrategies, c.WarmupStrategy), "cache.warmup_strategy must be one of %s", strings.Join(warmupStrategies, ", "))
+	v.require(c.WarmupPageSize > 0, "cache.warmup_page_size must be positive")
+	v.require(c.WarmupConcurrency > 0, "cache.warmup_concurrency must be positive")
+	v.require(c.AccessFlushInterval > 0, "cache.access_flush_interval must be positive")
+	v.require(c.BreakerFailures > 0, "cache.breaker_failures must be positive")
+	v.require(c.BreakerCooldown > 0, "cache.breaker_cooldown must be positive")
+	v.require(c.EarlyRefreshWindow >= 0, "cache.early_refresh_window must not be negative")
+	v.require(c.NegativeTTL >= 0, "cache.negative_ttl must not be negative")
+	v.require(c.BloomCapacity > 0, "cache.bloom_capacity must be positive")
+	v.require(c.BloomFPRate > 0 && c.BloomFPRate < 1, "cache.bloom_fp_rate must be between 0 and 1")
+	v.require(c.BloomRefreshInterval > 0, "cache.bloom_refresh_interval must be positive")
+	v.require(c.BloomLoadTimeout > 0, "cache.bloom_load_timeout must be positive")
+	v.require(c.LocalMaxEntries >= 0, "cache.local_max_entries must not be negative")
+	v.require(c.LocalMaxMB >= 0, "cache.local_max_mb must not be negative")
+	v.require(c.LocalTTL > 0, "cache.local_ttl must be positive")
+	return v.err()
+}
+
+func (c LogConfig) Validate() error {
+	var v validation
+	v.require(slices.Contains(logLevels, strings.ToLower(c.Level)), "log.level must be one of %s", strings.Join(logLevels, ", "))
+	v.require(slices.Contains(logFormats, strings.ToLower(c.Format)), "log.format must be one of %s", strings.Join(logFormats, ", "))
+	return v.err()
+}
@@ -0,0 +1,235 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "******"
+
+type field struct {
+	path   string
+	env    string
+	secret bool
+	unitMS bool
+	value  reflect.Value
+}
+
+// Load builds the config from defaults, the YAML or JSON file set by -config or CONFIG_FILE,
+// environment variables and flags, validates it and returns the arguments left after the flags.
+// Such arguments are a maintenance command, and then only the sections the commands use are validated.
+func Load(args []string) (*Config, []string, error) {
+	cfg := Default()
+	fields := cfg.fields()
+
+	flags := flag.NewFlagSet("main", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or JSON config file (env CONFIG_FILE)")
+	flagValues := make(map[string]*string, len(fields))
+	for _, f := range fields {
+		usage := "env " + f.env + ", default " + f.format()
+		flagValues[f.path] = flags.String(f.path, "", usage)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse flags: %w", err)
+	}
+
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile, fields); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for _, f := range fields {
+		if raw, ok := os.LookupEnv(f.env); ok && raw != "" {
+			if err := f.set(raw); err != nil {
+				return nil, nil, fmt.Errorf("invalid %s: %w", f.env, err)
+			}
+		}
+	}
+
+	var flagErr error
+	flags.Visit(func(fl *flag.Flag) {
+		for _, f := range fields {
+			if f.path == fl.Name {
+				if err := f.set(*flagValues[f.path]); err != nil {
+					flagErr = errors.Join(flagErr, fmt.Errorf("invalid -%s: %w", f.path, err))
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, nil, flagErr
+	}
+
+	validate := cfg.Validate
+	if flags.NArg() > 0 {
+		validate = cfg.ValidateCommand
+	}
+	if err := validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, flags.Args(), nil
+}
+
+// loadFile applies the values from a YAML file. JSON is a subset of YAML, so JSON files work too.
+func (c *Config) loadFile(path string, fields []field) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	byPath := make(map[string]field, len(fields))
+	for _, f := range fields {
+		byPath[f.path] = f
+	}
+
+	for section, sectionValues := range values {
+		sectionMap, ok := sectionValues.(map[string]any)
+		if !ok {
+			return fmt.Errorf("invalid config file %s: %s must be a mapping", path, section)
+		}
+		for key, value := range sectionMap {
+			f, ok := byPath[section+"."+key]
+			if !ok {
+				return fmt.Errorf("invalid config file %s: unknown key %s.%s", path, section, key)
+			}
+			if err := f.set(fileValue(value)); err != nil {
+				return fmt.Errorf("invalid config file %s: %s: %w", path, f.path, err)
+			}
+		}
+	}
+	return nil
+}
+
+func fileValue(value any) string {
+	list, ok := value.([]any)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+	items := make([]string, len(list))
+	for i, item := range list {
+		items[i] = fmt.Sprint(item)
+	}
+	return strings.Join(items, ",")
+}
+
+// String prints the effective config one setting per line with secrets redacted.
+func (c *Config) String() string {
+	var b strings.Builder
+	for _, f := range c.fields() {
+		fmt.Fprintf(&b, "%s=%s\n", f.path, f.format())
+	}
+	return b.String()
+}
+
+// LogValue logs the effective config as one attribute per setting with secrets redacted.
+func (c *Config) LogValue() slog.Value {
+	fields := c.fields()
+	attrs := make([]slog.Attr, len(fields))
+	for i, f := range fields {
+		attrs[i] = slog.String(f.path, f.format())
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func (f field) format() string {
+	var value string
+	switch v := f.value.Interface().(type) {
+	case []string:
+		value = strings.Join(v, ",")
+	default:
+		value = fmt.Sprint(v)
+	}
+	if f.secret && value != "" {
+		return redacted
+	}
+	return value
+}
+
+func (c *Config) fields() []field {
+	var fields []field
+	root := reflect.ValueOf(c).Elem()
+	for i := range root.NumField() {
+		section := root.Type().Field(i)
+		sectionValue := root.Field(i)
+		for j := range sectionValue.NumField() {
+			sf := section.Type.Field(j)
+			fields = append(fields, field{
+				path:   section.Tag.Get("yaml") + "." + sf.Tag.Get("yaml"),
+				env:    sf.Tag.Get("env"),
+				secret: sf.Tag.Get("secret") == "true",
+				unitMS: sf.Tag.Get("unit") == "ms",
+				value:  sectionValue.Field(j),
+			})
+		}
+	}
+	return fields
+}
+
+func (f field) set(raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch ptr := f.value.Addr().Interface().(type) {
+	case *string:
+		*ptr = raw
+	case *[]string:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*ptr = items
+	case *bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		*ptr = value
+	case *int:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		*ptr = value
+	case *int64:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		*ptr = value
+	case *float64:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		*ptr = value
+	case *time.Duration:
+		// settings named *_MS in the environment are plain numbers of milliseconds
+		if ms, err := strconv.ParseInt(raw, 10, 64); err == nil && f.unitMS {
+			*ptr = time.Duration(ms) * time.Millisecond
+			return nil
+		}
+		value, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		*ptr = value
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package consumer
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+const (
+	minBackoff = 100 * time.Millisecond
+	maxBackoff = 30 * time.Second
+)
+
+// backoff produces exponentially growing delays, each randomly jittered within [d/2, d].
+type backoff struct {
+	min     time.Duration
+	max     time.Duration
+	attempt int
+}
+
+func newBackoff() *backoff {
+	return &backoff{min: minBackoff, max: maxBackoff}
+}
+
+func (b *backoff) next() time.Duration {
+	delay := b.max
+	if b.attempt < 32 {
+		if d := b.min << b.attempt; d > 0 && d < b.max {
+			delay = d
+		}
+	}
+	b.attempt++
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func (b *backoff) reset() {
+	b.attempt = 0
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
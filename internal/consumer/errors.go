@@ -0,0 +1,24 @@
+package consumer
+
+import "errors"
+
+type RetryableError struct {
+	Err error
+}
+
+func NewRetryableError(err error) error {
+	return &RetryableError{Err: err}
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+func IsRetryable(err error) bool {
+	var retryableErr *RetryableError
+	return errors.As(err, &retryableErr)
+}
@@ -2,57 +2,502 @@ package consumer
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/karambo3a/wbtech_test_task/internal/config"
+	"github.com/karambo3a/wbtech_test_task/internal/logger"
+	"github.com/karambo3a/wbtech_test_task/internal/metrics"
 	"github.com/segmentio/kafka-go"
 )
 
 //go:generate mockgen -source=kafka_consumer.go -destination=../../test/mocks/kafka_consumer_mock.go
 
 type Consumer interface {
-	StartConsuming(processFunc func(message []byte) error)
-	Close() error
+	StartConsuming(processFunc func(ctx context.Context, message Message) error)
+	StartBatchConsuming(processBatchFunc func(ctx context.Context, messages []Message) []error)
+	Ping(ctx context.Context) error
+	Lag() int64
+	Close(ctx context.Context) error
 }
 
+const workerQueueSize = 64
+
 type ConsumerImpl struct {
-	Reader *kafka.Reader
+	Reader           *kafka.Reader
+	DeadLetterWriter *kafka.Writer
+	Workers          int
+	BatchSize        int
+	BatchTimeout     time.Duration
+	ProcessTimeout   time.Duration
+
+	stop context.CancelFunc
+	wg   sync.WaitGroup
 }
 
-func NewConsumer() *ConsumerImpl {
-	return &ConsumerImpl{
+func NewConsumer(cfg config.KafkaConfig) *ConsumerImpl {
+	consumer := &ConsumerImpl{
 		Reader: kafka.NewReader(kafka.ReaderConfig{
-			Brokers: []string{os.Getenv("KAFKA_BROKERS_CONS")},
-			Topic:   "order",
-			GroupID: "order-service-group",
+			Brokers: cfg.Brokers,
+			Topic:   cfg.Topic,
+			GroupID: cfg.GroupID,
 		}),
+		Workers:        cfg.Workers,
+		BatchSize:      cfg.BatchSize,
+		BatchTimeout:   cfg.BatchTimeout,
+		ProcessTimeout: cfg.ProcessTimeout,
+	}
+
+	if cfg.DLQTopic != "" {
+		consumer.DeadLetterWriter = &kafka.Writer{
+			Addr:                   kafka.TCP(cfg.Brokers...),
+			Topic:                  cfg.DLQTopic,
+			AllowAutoTopicCreation: true,
+		}
+	} else {
+		slog.Warn("kafka dead-letter topic is not set, messages that fail processing are committed and dropped")
 	}
+
+	return consumer
 }
 
-func (c *ConsumerImpl) StartConsuming(processFunc func(message []byte) error) {
+func (c *ConsumerImpl) StartConsuming(processFunc func(ctx context.Context, message Message) error) {
+	workers := max(c.Workers, 1)
+	ctx := c.start()
+	tracker := newOffsetTracker()
+
+	processed := make(chan kafka.Message, workers*workerQueueSize)
+	queues := make([]chan kafka.Message, workers)
+	var workersWG sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan kafka.Message, workerQueueSize)
+		workersWG.Add(1)
+		go func() {
+			defer workersWG.Done()
+			c.runWorker(ctx, queues[i], processed, processFunc)
+		}()
+	}
+
+	c.wg.Add(2)
 	go func() {
+		defer c.wg.Done()
+		c.runCommitter(tracker, processed)
+	}()
+	go func() {
+		defer c.wg.Done()
+		defer func() {
+			for _, queue := range queues {
+				close(queue)
+			}
+			workersWG.Wait()
+			close(processed)
+		}()
+
+		fetchBackoff := newBackoff()
 		for {
-			msg, err := c.Reader.FetchMessage(context.Background())
+			msg, err := c.Reader.FetchMessage(ctx)
+			if ctx.Err() != nil {
+				slog.Info("kafka consumer stopped")
+				return
+			}
+			if errors.Is(err, io.EOF) {
+				slog.Info("kafka reader closed")
+				return
+			}
 			if err != nil {
-				log.Printf("kafka error: %v", err)
+				delay := fetchBackoff.next()
+				slog.Warn("failed to fetch message, retrying", logger.Err(err), "delay", delay.String())
+				_ = sleepContext(ctx, delay)
 				continue
 			}
+			fetchBackoff.reset()
+			observeFetched(msg)
 
-			if err := processFunc(msg.Value); err != nil {
-				log.Printf("processing error: %v", err)
-			}
-			if err = c.Reader.CommitMessages(context.Background(), msg); err != nil {
-				log.Printf("kafka error: %v", err)
+			if !tracker.track(msg) {
+				slog.Info("skipping already committed message", "partition", msg.Partition, "offset", msg.Offset)
 				continue
 			}
+			queues[workerIndex(msg, workers)] <- msg
 		}
 	}()
 }
 
-func (c *ConsumerImpl) Close() error {
-	if err := c.Reader.Close(); err != nil {
-		return fmt.Errorf("failed to close Kafka consumer: %w", err)
+func (c *ConsumerImpl) start() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stop = cancel
+	return ctx
+}
+
+// workerIndex keeps messages with the same key on the same worker, so updates of one order
+// are processed in the order they were produced.
+func workerIndex(msg kafka.Message, workers int) int {
+	if len(msg.Key) == 0 {
+		return msg.Partition % workers
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(msg.Key)
+	return int(h.Sum32() % uint32(workers))
+}
+
+func (c *ConsumerImpl) runWorker(ctx context.Context, queue <-chan kafka.Message, processed chan<- kafka.Message, processFunc func(ctx context.Context, message Message) error) {
+	for msg := range queue {
+		// messages that were queued but not started before shutdown are left uncommitted
+		if ctx.Err() != nil {
+			continue
+		}
+		if err := c.processMessage(ctx, msg, processFunc); err != nil {
+			slog.Error("message is not processed", logger.Err(err), "partition", msg.Partition, "offset", msg.Offset)
+			continue
+		}
+		processed <- msg
+	}
+}
+
+func (c *ConsumerImpl) runCommitter(tracker *offsetTracker, processed <-chan kafka.Message) {
+	for msg := range processed {
+		commitMsg, ok := tracker.markDone(msg)
+		if !ok {
+			continue
+		}
+		if err := c.Reader.CommitMessages(context.Background(), commitMsg); err != nil {
+			slog.Error("failed to commit message", logger.Err(err), "partition", commitMsg.Partition, "offset", commitMsg.Offset)
+		}
+	}
+}
+
+// StartBatchConsuming passes up to BatchSize messages, gathered for at most BatchTimeout,
+// to processBatchFunc at once. Each batch is split between Workers by key like in StartConsuming,
+// and the parts are processed in parallel while the next batch is fetched.
+// processBatchFunc returns one error per message. With BatchSize <= 1 it falls back to StartConsuming.
+func (c *ConsumerImpl) StartBatchConsuming(processBatchFunc func(ctx context.Context, messages []Message) []error) {
+	if c.BatchSize <= 1 {
+		c.StartConsuming(func(ctx context.Context, message Message) error {
+			return processBatchFunc(ctx, []Message{message})[0]
+		})
+		return
+	}
+
+	workers := max(c.Workers, 1)
+	ctx := c.start()
+	tracker := newOffsetTracker()
+
+	processed := make(chan kafka.Message, workers*c.BatchSize)
+	// a worker is handed the next part of a batch while it processes the current one
+	queues := make([]chan []kafka.Message, workers)
+	var workersWG sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan []kafka.Message, 1)
+		workersWG.Add(1)
+		go func() {
+			defer workersWG.Done()
+			c.runBatchWorker(ctx, queues[i], processed, processBatchFunc)
+		}()
+	}
+
+	c.wg.Add(2)
+	go func() {
+		defer c.wg.Done()
+		c.runCommitter(tracker, processed)
+	}()
+	go func() {
+		defer c.wg.Done()
+		defer func() {
+			for _, queue := range queues {
+				close(queue)
+			}
+			workersWG.Wait()
+			close(processed)
+		}()
+
+		for {
+			batch, err := c.fetchBatch(ctx)
+			fresh := batch[:0]
+			for _, msg := range batch {
+				if !tracker.track(msg) {
+					slog.Info("skipping already committed message", "partition", msg.Partition, "offset", msg.Offset)
+					continue
+				}
+				fresh = append(fresh, msg)
+			}
+			for i, part := range splitBatch(fresh, workers) {
+				if len(part) > 0 {
+					queues[i] <- part
+				}
+			}
+			if ctx.Err() != nil {
+				slog.Info("kafka consumer stopped")
+				return
+			}
+			if errors.Is(err, io.EOF) {
+				slog.Info("kafka reader closed")
+				return
+			}
+		}
+	}()
+}
+
+// splitBatch groups the messages of a batch by workerIndex, keeping their order.
+func splitBatch(batch []kafka.Message, workers int) [][]kafka.Message {
+	parts := make([][]kafka.Message, workers)
+	for _, msg := range batch {
+		i := workerIndex(msg, workers)
+		parts[i] = append(parts[i], msg)
+	}
+	return parts
+}
+
+func (c *ConsumerImpl) runBatchWorker(ctx context.Context, queue <-chan []kafka.Message, processed chan<- kafka.Message, processBatchFunc func(ctx context.Context, messages []Message) []error) {
+	for batch := range queue {
+		// batches that were queued but not started before shutdown are left uncommitted
+		if ctx.Err() != nil {
+			continue
+		}
+		if err := c.processBatch(ctx, batch, processBatchFunc); err != nil {
+			slog.Error("batch is not processed", logger.Err(err), "size", len(batch))
+			continue
+		}
+		for _, msg := range batch {
+			processed <- msg
+		}
+	}
+}
+
+func observeFetched(msg kafka.Message) {
+	metrics.MessagesConsumed.Inc()
+	metrics.SetConsumerLag(msg.Partition, msg.HighWaterMark-msg.Offset-1)
+}
+
+func (c *ConsumerImpl) fetchBatch(ctx context.Context) ([]kafka.Message, error) {
+	batch := make([]kafka.Message, 0, c.BatchSize)
+	fetchBackoff := newBackoff()
+
+	var deadline time.Time
+	for len(batch) < c.BatchSize {
+		fetchCtx, cancel := ctx, context.CancelFunc(func() {})
+		if len(batch) > 0 {
+			fetchCtx, cancel = context.WithDeadline(ctx, deadline)
+		}
+		msg, err := c.Reader.FetchMessage(fetchCtx)
+		cancel()
+
+		if ctx.Err() != nil {
+			return batch, ctx.Err()
+		}
+		if len(batch) > 0 && errors.Is(err, context.DeadlineExceeded) {
+			break
+		}
+		if errors.Is(err, io.EOF) {
+			return batch, err
+		}
+		if err != nil {
+			delay := fetchBackoff.next()
+			slog.Warn("failed to fetch message, retrying", logger.Err(err), "delay", delay.String())
+			_ = sleepContext(ctx, delay)
+			continue
+		}
+		fetchBackoff.reset()
+		observeFetched(msg)
+
+		if len(batch) == 0 {
+			deadline = time.Now().Add(c.BatchTimeout)
+		}
+		batch = append(batch, msg)
+	}
+	return batch, nil
+}
+
+// processBatch retries the messages that failed transiently and sends permanently failed ones
+// to the dead-letter topic, so the whole batch may be committed once it returns nil.
+func (c *ConsumerImpl) processBatch(ctx context.Context, batch []kafka.Message, processBatchFunc func(ctx context.Context, messages []Message) []error) error {
+	retryBackoff := newBackoff()
+	for pending := batch; len(pending) > 0; {
+		messages := make([]Message, len(pending))
+		for i, msg := range pending {
+			messages[i] = newMessage(msg)
+		}
+
+		processCtx, cancel := c.processContext(ctx)
+		errs := processBatchFunc(processCtx, messages)
+		cancel()
+
+		var retry []kafka.Message
+		for i, err := range errs {
+			if err == nil {
+				continue
+			}
+			if IsRetryable(err) {
+				retry = append(retry, pending[i])
+				continue
+			}
+
+			msgCtx := logger.WithCorrelationID(ctx, messages[i].CorrelationID)
+			slog.ErrorContext(msgCtx, "failed to process message", logger.Err(err), "partition", pending[i].Partition, "offset", pending[i].Offset)
+			if err := c.sendToDeadLetterWithRetry(msgCtx, pending[i], err); err != nil {
+				return err
+			}
+		}
+
+		if len(retry) == 0 {
+			return nil
+		}
+
+		delay := retryBackoff.next()
+		slog.Warn("transient processing error, retrying", "messages", len(retry), "delay", delay.String())
+		if err := sleepContext(ctx, delay); err != nil {
+			return fmt.Errorf("batch of %d messages is not processed: %w", len(batch), err)
+		}
+		pending = retry
 	}
 	return nil
 }
+
+// processMessage returns only when the message may be committed: it was processed,
+// or it failed permanently and was handed over to the dead-letter topic.
+// Transient failures are retried until they succeed or ctx is done.
+func (c *ConsumerImpl) processMessage(ctx context.Context, msg kafka.Message, processFunc func(ctx context.Context, message Message) error) error {
+	message := newMessage(msg)
+	ctx = logger.WithCorrelationID(ctx, message.CorrelationID)
+	retryBackoff := newBackoff()
+	for {
+		processCtx, cancel := c.processContext(ctx)
+		err := processFunc(processCtx, message)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		if IsRetryable(err) {
+			delay := retryBackoff.next()
+			slog.WarnContext(ctx, "transient processing error, retrying", logger.Err(err), "delay", delay.String())
+			if err := sleepContext(ctx, delay); err != nil {
+				return fmt.Errorf("message offset=%d partition=%d is not processed: %w", msg.Offset, msg.Partition, err)
+			}
+			continue
+		}
+
+		slog.ErrorContext(ctx, "failed to process message", logger.Err(err), "partition", msg.Partition, "offset", msg.Offset)
+		return c.sendToDeadLetterWithRetry(ctx, msg, err)
+	}
+}
+
+// processContext bounds a single processing attempt by ProcessTimeout. It is not cancelled
+// on shutdown, so the message that is already being processed is finished and committed.
+func (c *ConsumerImpl) processContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx = context.WithoutCancel(ctx)
+	if c.ProcessTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.ProcessTimeout)
+}
+
+func (c *ConsumerImpl) sendToDeadLetterWithRetry(ctx context.Context, msg kafka.Message, processErr error) error {
+	retryBackoff := newBackoff()
+	for {
+		err := c.sendToDeadLetter(ctx, msg, processErr)
+		if err == nil {
+			return nil
+		}
+
+		delay := retryBackoff.next()
+		slog.WarnContext(ctx, "failed to send message to dead-letter topic, retrying", logger.Err(err), "delay", delay.String())
+		if err := sleepContext(ctx, delay); err != nil {
+			return fmt.Errorf("message offset=%d partition=%d is not sent to dead-letter topic: %w", msg.Offset, msg.Partition, err)
+		}
+	}
+}
+
+func (c *ConsumerImpl) sendToDeadLetter(ctx context.Context, msg kafka.Message, processErr error) error {
+	if c.DeadLetterWriter == nil {
+		return nil
+	}
+
+	err := c.DeadLetterWriter.WriteMessages(context.WithoutCancel(ctx), deadLetterMessage(msg, processErr, time.Now()))
+	if err != nil {
+		return fmt.Errorf("failed to send message offset=%d partition=%d to dead-letter topic: %w", msg.Offset, msg.Partition, err)
+	}
+
+	slog.InfoContext(ctx, "message sent to dead-letter topic", "partition", msg.Partition, "offset", msg.Offset, "topic", c.DeadLetterWriter.Topic)
+	return nil
+}
+
+// deadLetterMessage copies msg for the dead-letter topic with headers describing where and why it failed.
+func deadLetterMessage(msg kafka.Message, processErr error, failedAt time.Time) kafka.Message {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+7)
+	headers = append(headers, msg.Headers...)
+	// the ID derived from the original position keeps the same message traceable after it is moved
+	if _, ok := headerCorrelationID(msg); !ok {
+		headers = append(headers, kafka.Header{Key: "correlation-id", Value: []byte(correlationID(msg))})
+	}
+	headers = append(headers,
+		kafka.Header{Key: "dlq-original-topic", Value: []byte(msg.Topic)},
+		kafka.Header{Key: "dlq-original-key", Value: msg.Key},
+		kafka.Header{Key: "dlq-original-partition", Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: "dlq-original-offset", Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: "dlq-error", Value: []byte(processErr.Error())},
+		kafka.Header{Key: "dlq-failed-at", Value: []byte(failedAt.UTC().Format(time.RFC3339Nano))},
+	)
+
+	return kafka.Message{
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	}
+}
+
+// Ping checks that at least one of the brokers accepts connections.
+func (c *ConsumerImpl) Ping(ctx context.Context) error {
+	var errs []error
+	for _, broker := range c.Reader.Config().Brokers {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		_ = conn.Close()
+		return nil
+	}
+	return fmt.Errorf("failed to connect to Kafka: %w", errors.Join(errs...))
+}
+
+// Lag returns how many messages the reader is behind the end of the partition,
+// as of the last fetch.
+func (c *ConsumerImpl) Lag() int64 {
+	return c.Reader.Stats().Lag
+}
+
+// Close stops fetching, waits until ctx is done for in-flight messages to be processed and committed
+// and then closes the reader and the dead-letter writer. Messages still in flight when ctx is done
+// are left uncommitted and delivered again.
+func (c *ConsumerImpl) Close(ctx context.Context) error {
+	if c.stop != nil {
+		c.stop()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	var waitErr error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		waitErr = fmt.Errorf("failed to wait for in-flight messages: %w", ctx.Err())
+	}
+
+	if err := c.Reader.Close(); err != nil {
+		return errors.Join(waitErr, fmt.Errorf("failed to close Kafka consumer: %w", err))
+	}
+	if c.DeadLetterWriter != nil {
+		if err := c.DeadLetterWriter.Close(); err != nil {
+			return errors.Join(waitErr, fmt.Errorf("failed to close Kafka dead-letter writer: %w", err))
+		}
+	}
+	return waitErr
+}
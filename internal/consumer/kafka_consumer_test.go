@@ -0,0 +1,117 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerIndex(t *testing.T) {
+	const workers = 4
+	used := make(map[int]bool)
+	for i := range 100 {
+		key := []byte(fmt.Sprintf("order_uid%d", i))
+		index := workerIndex(kafka.Message{Key: key, Partition: i % 3}, workers)
+		assert.GreaterOrEqual(t, index, 0)
+		assert.Less(t, index, workers)
+		used[index] = true
+
+		// the same key goes to the same worker whatever its partition
+		for partition := range 3 {
+			assert.Equal(t, index, workerIndex(kafka.Message{Key: key, Partition: partition}, workers))
+		}
+	}
+	assert.Len(t, used, workers, "keys are spread over all workers")
+
+	// messages without a key stay on the worker of their partition
+	assert.Equal(t, 1, workerIndex(kafka.Message{Partition: 5}, workers))
+	assert.Equal(t, workerIndex(kafka.Message{Partition: 2}, workers), workerIndex(kafka.Message{Partition: 2}, workers))
+}
+
+func TestSplitBatch(t *testing.T) {
+	const workers = 3
+	var batch []kafka.Message
+	for i := range 30 {
+		batch = append(batch, kafka.Message{Key: []byte(fmt.Sprintf("order_uid%d", i%10)), Offset: int64(i)})
+	}
+
+	parts := splitBatch(batch, workers)
+	assert.Len(t, parts, workers)
+	total := 0
+	for i, part := range parts {
+		total += len(part)
+		for j, msg := range part {
+			assert.Equal(t, i, workerIndex(msg, workers))
+			// updates of one order keep the order they were fetched in
+			if j > 0 {
+				assert.Less(t, part[j-1].Offset, msg.Offset)
+			}
+		}
+	}
+	assert.Equal(t, len(batch), total)
+}
+
+func TestConsumerCloseDeadline(t *testing.T) {
+	c := &ConsumerImpl{Reader: kafka.NewReader(kafka.ReaderConfig{Brokers: []string{"localhost:9092"}, Topic: "order", GroupID: "test"})}
+	// a worker that is still processing a message
+	c.wg.Add(1)
+	defer c.wg.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := c.Close(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestDeadLetterMessage(t *testing.T) {
+	failedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	processErr := errors.New("failed to parse order json")
+	headers := func(msg kafka.Message) map[string]string {
+		values := make(map[string]string)
+		for _, header := range msg.Headers {
+			values[header.Key] = string(header.Value)
+		}
+		return values
+	}
+
+	t.Run("original position", func(t *testing.T) {
+		msg := kafka.Message{Topic: "order", Partition: 2, Offset: 42, Key: []byte("order_uid1"), Value: []byte(`{"order_uid":`)}
+		dlq := deadLetterMessage(msg, processErr, failedAt)
+
+		assert.Equal(t, msg.Key, dlq.Key)
+		assert.Equal(t, msg.Value, dlq.Value)
+		assert.Empty(t, dlq.Topic, "the topic is set by the writer")
+		assert.Equal(t, map[string]string{
+			"correlation-id":         "order-2-42",
+			"dlq-original-topic":     "order",
+			"dlq-original-key":       "order_uid1",
+			"dlq-original-partition": "2",
+			"dlq-original-offset":    "42",
+			"dlq-error":              "failed to parse order json",
+			"dlq-failed-at":          "2024-01-01T09:00:00Z",
+		}, headers(dlq))
+	})
+
+	t.Run("correlation id header is kept", func(t *testing.T) {
+		msg := kafka.Message{
+			Topic:     "order",
+			Partition: 0,
+			Offset:    7,
+			Headers:   []kafka.Header{{Key: "X-Correlation-ID", Value: []byte("request-1")}},
+		}
+		dlq := deadLetterMessage(msg, processErr, failedAt)
+
+		values := headers(dlq)
+		assert.Equal(t, "request-1", values["X-Correlation-ID"])
+		assert.NotContains(t, values, "correlation-id")
+		assert.Equal(t, "7", values["dlq-original-offset"])
+		assert.Len(t, dlq.Headers, 7)
+	})
+}
@@ -0,0 +1,51 @@
+package consumer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// correlationHeaders are the header keys a producer may set the correlation ID in, compared case-insensitively.
+var correlationHeaders = []string{"correlation-id", "correlation_id", "x-correlation-id"}
+
+// Message is a consumed record together with its position in Kafka.
+// CorrelationID is taken from the headers, or built from the position when there is none.
+type Message struct {
+	Topic         string
+	Partition     int
+	Offset        int64
+	Key           []byte
+	Value         []byte
+	CorrelationID string
+}
+
+func newMessage(msg kafka.Message) Message {
+	return Message{
+		Topic:         msg.Topic,
+		Partition:     msg.Partition,
+		Offset:        msg.Offset,
+		Key:           msg.Key,
+		Value:         msg.Value,
+		CorrelationID: correlationID(msg),
+	}
+}
+
+func correlationID(msg kafka.Message) string {
+	if id, ok := headerCorrelationID(msg); ok {
+		return id
+	}
+	return msg.Topic + "-" + strconv.Itoa(msg.Partition) + "-" + strconv.FormatInt(msg.Offset, 10)
+}
+
+func headerCorrelationID(msg kafka.Message) (string, bool) {
+	for _, header := range msg.Headers {
+		for _, key := range correlationHeaders {
+			if strings.EqualFold(header.Key, key) && len(header.Value) > 0 {
+				return string(header.Value), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,97 @@
+package consumer
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type partitionKey struct {
+	topic     string
+	partition int
+}
+
+type partitionOffsets struct {
+	pending []int64
+	// tracked holds the pending offsets, true once an offset is processed
+	tracked map[int64]bool
+	// committed is the highest offset that was reported as committable, -1 for none
+	committed int64
+}
+
+func newPartitionOffsets(committed int64) *partitionOffsets {
+	return &partitionOffsets{tracked: make(map[int64]bool), committed: committed}
+}
+
+// offsetTracker remembers fetched offsets per partition and reports the highest offset
+// below which every message has been processed, so out-of-order completions by
+// workers never commit past a message that is still in flight.
+//
+// After a rebalance the reader fetches a partition again from its committed offset.
+// Offsets at or below the committed watermark are reported as duplicates, and an offset
+// at or below the last fetched one starts a new generation that drops the pending state
+// of the partition, so messages of the previous assignment no longer hold commits back.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[partitionKey]*partitionOffsets
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[partitionKey]*partitionOffsets)}
+}
+
+// track returns false for a message that was already committed and must not be processed again.
+func (t *offsetTracker) track(msg kafka.Message) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := partitionKey{topic: msg.Topic, partition: msg.Partition}
+	offsets, ok := t.partitions[key]
+	if !ok {
+		offsets = newPartitionOffsets(-1)
+		t.partitions[key] = offsets
+	}
+	if msg.Offset <= offsets.committed {
+		return false
+	}
+	if n := len(offsets.pending); n > 0 && msg.Offset <= offsets.pending[n-1] {
+		offsets = newPartitionOffsets(offsets.committed)
+		t.partitions[key] = offsets
+	}
+
+	offsets.pending = append(offsets.pending, msg.Offset)
+	offsets.tracked[msg.Offset] = false
+	return true
+}
+
+func (t *offsetTracker) markDone(msg kafka.Message) (kafka.Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	offsets, ok := t.partitions[partitionKey{topic: msg.Topic, partition: msg.Partition}]
+	if !ok {
+		return kafka.Message{}, false
+	}
+	// offsets dropped with a previous generation or already committed are not pending
+	if _, ok := offsets.tracked[msg.Offset]; !ok {
+		return kafka.Message{}, false
+	}
+	offsets.tracked[msg.Offset] = true
+
+	committable := int64(-1)
+	for len(offsets.pending) > 0 {
+		offset := offsets.pending[0]
+		if !offsets.tracked[offset] {
+			break
+		}
+		delete(offsets.tracked, offset)
+		offsets.pending = offsets.pending[1:]
+		committable = offset
+	}
+
+	if committable < 0 {
+		return kafka.Message{}, false
+	}
+	offsets.committed = committable
+	return kafka.Message{Topic: msg.Topic, Partition: msg.Partition, Offset: committable}, true
+}
@@ -0,0 +1,111 @@
+package consumer
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	msg := func(partition int, offset int64) kafka.Message {
+		return kafka.Message{Topic: "order", Partition: partition, Offset: offset}
+	}
+	type done struct {
+		msg kafka.Message
+		// commit is the offset expected to become committable, -1 for none
+		commit int64
+	}
+
+	tests := []struct {
+		name    string
+		tracked []kafka.Message
+		done    []done
+	}{
+		{
+			name:    "in order",
+			tracked: []kafka.Message{msg(0, 0), msg(0, 1), msg(0, 2)},
+			done:    []done{{msg(0, 0), 0}, {msg(0, 1), 1}, {msg(0, 2), 2}},
+		},
+		{
+			name:    "out of order",
+			tracked: []kafka.Message{msg(0, 0), msg(0, 1), msg(0, 2), msg(0, 3)},
+			done:    []done{{msg(0, 2), -1}, {msg(0, 1), -1}, {msg(0, 0), 2}, {msg(0, 3), 3}},
+		},
+		{
+			name:    "last done first",
+			tracked: []kafka.Message{msg(0, 0), msg(0, 1), msg(0, 2)},
+			done:    []done{{msg(0, 2), -1}, {msg(0, 0), 0}, {msg(0, 1), 2}},
+		},
+		{
+			name: "gaps in offsets",
+			// compacted topics and transaction markers leave gaps between fetched offsets
+			tracked: []kafka.Message{msg(0, 3), msg(0, 7), msg(0, 20)},
+			done:    []done{{msg(0, 7), -1}, {msg(0, 3), 7}, {msg(0, 20), 20}},
+		},
+		{
+			name:    "several partitions",
+			tracked: []kafka.Message{msg(0, 0), msg(1, 0), msg(0, 1), msg(1, 1)},
+			done:    []done{{msg(1, 1), -1}, {msg(0, 0), 0}, {msg(1, 0), 1}, {msg(0, 1), 1}},
+		},
+		{
+			name:    "untracked partition",
+			tracked: []kafka.Message{msg(0, 0)},
+			done:    []done{{msg(1, 0), -1}, {msg(0, 0), 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newOffsetTracker()
+			for _, m := range tt.tracked {
+				tracker.track(m)
+			}
+			for _, d := range tt.done {
+				commit, ok := tracker.markDone(d.msg)
+				if d.commit < 0 {
+					assert.False(t, ok, "offset %d of partition %d", d.msg.Offset, d.msg.Partition)
+					continue
+				}
+				if assert.True(t, ok, "offset %d of partition %d", d.msg.Offset, d.msg.Partition) {
+					assert.Equal(t, msg(d.msg.Partition, d.commit), commit)
+				}
+			}
+		})
+	}
+}
+
+func TestOffsetTrackerReplay(t *testing.T) {
+	msg := func(offset int64) kafka.Message {
+		return kafka.Message{Topic: "order", Partition: 0, Offset: offset}
+	}
+	tracker := newOffsetTracker()
+	for offset := range int64(4) {
+		assert.True(t, tracker.track(msg(offset)))
+	}
+	commit, ok := tracker.markDone(msg(0))
+	assert.True(t, ok)
+	assert.Equal(t, msg(0), commit)
+	_, ok = tracker.markDone(msg(2))
+	assert.False(t, ok)
+
+	// after a rebalance the reader fetches the partition again from its committed offset
+	assert.False(t, tracker.track(msg(0)), "committed offset is a duplicate")
+	assert.True(t, tracker.track(msg(1)))
+	assert.True(t, tracker.track(msg(2)))
+
+	// offset 3 of the previous generation is no longer pending and does not hold commits back
+	_, ok = tracker.markDone(msg(3))
+	assert.False(t, ok)
+	_, ok = tracker.markDone(msg(2))
+	assert.False(t, ok)
+	commit, ok = tracker.markDone(msg(1))
+	assert.True(t, ok)
+	assert.Equal(t, msg(2), commit)
+
+	// a repeated completion of a committed offset commits nothing
+	_, ok = tracker.markDone(msg(1))
+	assert.False(t, ok)
+	assert.False(t, tracker.track(msg(2)))
+	assert.True(t, tracker.track(msg(3)))
+}
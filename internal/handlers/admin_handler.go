@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+	maxPayloadSize   = 1 << 20
+)
+
+func (h *handler) initAdminRouts(r chi.Router) {
+	r.Use(h.adminAuth)
+	r.Get("/ingest-failures", h.ListIngestFailures)
+	r.Get("/ingest-failures/{id}", h.GetIngestFailure)
+	r.Put("/ingest-failures/{id}", h.UpdateIngestFailure)
+	r.Post("/ingest-failures/{id}/reprocess", h.ReprocessIngestFailure)
+	r.Delete("/ingest-failures/{id}", h.DiscardIngestFailure)
+}
+
+func (h *handler) adminAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := "Bearer " + h.adminToken
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+			writeProblem(w, r, http.StatusUnauthorized, "invalid admin token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *handler) ListIngestFailures(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt(r, "limit", defaultListLimit)
+	if err != nil || limit <= 0 || limit > maxListLimit {
+		writeProblem(w, r, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxListLimit))
+		return
+	}
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil || offset < 0 {
+		writeProblem(w, r, http.StatusBadRequest, "offset must not be negative")
+		return
+	}
+
+	failures, err := h.service.ListIngestFailures(r.Context(), limit, offset)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, failures)
+}
+
+func (h *handler) GetIngestFailure(w http.ResponseWriter, r *http.Request) {
+	id, ok := ingestFailureID(w, r)
+	if !ok {
+		return
+	}
+
+	failure, err := h.service.GetIngestFailure(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, failure)
+}
+
+func (h *handler) UpdateIngestFailure(w http.ResponseWriter, r *http.Request) {
+	id, ok := ingestFailureID(w, r)
+	if !ok {
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "failed to read payload")
+		return
+	}
+	if len(payload) == 0 {
+		writeProblem(w, r, http.StatusBadRequest, "empty payload")
+		return
+	}
+
+	failure, err := h.service.UpdateIngestFailure(r.Context(), id, string(payload))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, failure)
+}
+
+func (h *handler) ReprocessIngestFailure(w http.ResponseWriter, r *http.Request) {
+	id, ok := ingestFailureID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ReprocessIngestFailure(r.Context(), id); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) DiscardIngestFailure(w http.ResponseWriter, r *http.Request) {
+	id, ok := ingestFailureID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DiscardIngestFailure(r.Context(), id); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func ingestFailureID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeProblem(w, r, http.StatusBadRequest, "invalid id")
+		return 0, false
+	}
+	return id, true
+}
+
+func queryInt(r *http.Request, name string, defaultValue int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(value)
+}
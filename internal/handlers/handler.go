@@ -2,59 +2,128 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/karambo3a/wbtech_test_task/internal/health"
+	"github.com/karambo3a/wbtech_test_task/internal/logger"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
 	"github.com/karambo3a/wbtech_test_task/internal/service"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type handler struct {
-	service *service.Service
+	service    *service.Service
+	health     *health.Health
+	adminToken string
 }
 
-func NewHandler(s *service.Service) *handler {
-	return &handler{service: s}
+func NewHandler(s *service.Service, health *health.Health, adminToken string) *handler {
+	return &handler{
+		service:    s,
+		health:     health,
+		adminToken: adminToken,
+	}
 }
 
 func (h *handler) InitRouts() http.Handler {
 	r := chi.NewRouter()
-	r.Use(middleware.Logger)
-	r.Get("/order/{order_uid}", h.GetOrder)
+	r.Use(middleware.RequestID, withRequestID, observeRequests)
+	// probes and scrapes are polled often, so they are kept out of the request log
+	r.Get("/healthz", h.Liveness)
+	r.Get("/readyz", h.Readiness)
+	r.Handle("/metrics", promhttp.Handler())
+
+	r.Group(h.initAPIRouts)
 	return r
 }
 
+func (h *handler) initAPIRouts(r chi.Router) {
+	r.Use(logRequests)
+	r.Get("/order/{order_uid}", h.GetOrder)
+	r.Get("/orders", h.ListOrders)
+	if h.adminToken != "" {
+		r.Route("/admin", h.initAdminRouts)
+	} else {
+		slog.Warn("ADMIN_TOKEN is not set, admin API is disabled")
+	}
+}
+
 func (h *handler) GetOrder(w http.ResponseWriter, r *http.Request) {
 	orderUID := chi.URLParam(r, "order_uid")
-	log.Printf("order_uid=%s\n", orderUID)
 
-	w.Header().Set("Content-Type", "application/json")
 	if orderUID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": "empty order_uid"}); err != nil {
-			log.Println("failed to encode response")
-		}
+		writeProblem(w, r, http.StatusBadRequest, "empty order_uid")
 		return
 	}
 
-	order, err := h.service.GetOrder(orderUID)
+	order, err := h.service.GetOrder(r.Context(), orderUID)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": err.Error()}); err != nil {
-			log.Println("failed to encode response")
-		}
+		writeServiceError(w, r, err)
 		return
 	}
 
 	// to get response from client
 	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
 	if err = json.NewEncoder(w).Encode(order); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		if err := json.NewEncoder(w).Encode(map[string]string{"error": err.Error()}); err != nil {
-			log.Println("failed to encode response")
-		}
+		slog.ErrorContext(r.Context(), "failed to encode order", "order_uid", orderUID, logger.Err(err))
+		return
+	}
+	slog.DebugContext(r.Context(), "order sent", "order_uid", orderUID)
+}
+
+func (h *handler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt(r, "limit", defaultListLimit)
+	if err != nil || limit <= 0 || limit > maxListLimit {
+		writeProblem(w, r, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxListLimit))
+		return
+	}
+
+	query := r.URL.Query()
+	filter := model.OrderFilter{
+		CustomerID:      query.Get("customer_id"),
+		TrackNumber:     query.Get("track_number"),
+		DeliveryService: query.Get("delivery_service"),
+		Locale:          query.Get("locale"),
+		Currency:        query.Get("currency"),
+		Provider:        query.Get("provider"),
+	}
+	if filter.CreatedFrom, err = queryTime(r, "created_from"); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "created_from must be an RFC 3339 time")
+		return
+	}
+	if filter.CreatedTo, err = queryTime(r, "created_to"); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, "created_to must be an RFC 3339 time")
+		return
+	}
+
+	page, err := h.service.ListOrders(r.Context(), filter, query.Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
-	log.Printf("order with order_uid=%s sent\n", orderUID)
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	writeJSON(w, r, http.StatusOK, page)
+}
+
+func queryTime(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", logger.Err(err))
+	}
 }
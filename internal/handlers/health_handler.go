@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/karambo3a/wbtech_test_task/internal/health"
+)
+
+// Liveness only tells that the process serves HTTP, so dependency outages do not get it restarted.
+func (h *handler) Liveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, http.StatusOK, map[string]string{"status": health.StatusOK})
+}
+
+func (h *handler) Readiness(w http.ResponseWriter, r *http.Request) {
+	report := h.health.Check(r.Context())
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, r, status, report)
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+	"github.com/karambo3a/wbtech_test_task/internal/logger"
+	"github.com/karambo3a/wbtech_test_task/internal/validation"
+)
+
+// problem is an RFC 7807 problem details response.
+type problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Violations []validation.Violation `json:"violations,omitempty"`
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	writeProblemDetails(w, r, &problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+	})
+}
+
+func writeProblemDetails(w http.ResponseWriter, r *http.Request, p *problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", logger.Err(err))
+	}
+}
+
+// writeServiceError maps domain errors to HTTP statuses. Details of server-side
+// failures are logged but not exposed to clients.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErr *validation.Error
+	switch {
+	case errors.As(err, &validationErr):
+		writeProblemDetails(w, r, &problem{
+			Type:       "about:blank",
+			Title:      http.StatusText(http.StatusBadRequest),
+			Status:     http.StatusBadRequest,
+			Detail:     err.Error(),
+			Instance:   r.URL.Path,
+			Violations: validationErr.Violations,
+		})
+	case errors.Is(err, apperror.ErrInvalidInput):
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+	case errors.Is(err, apperror.ErrNotFound):
+		writeProblem(w, r, http.StatusNotFound, err.Error())
+	case errors.Is(err, apperror.ErrConflict):
+		writeProblem(w, r, http.StatusConflict, err.Error())
+	case errors.Is(err, apperror.ErrUnavailable):
+		slog.WarnContext(r.Context(), "service unavailable", logger.Err(err))
+		writeProblem(w, r, http.StatusServiceUnavailable, "dependency is temporarily unavailable, retry later")
+	default:
+		slog.ErrorContext(r.Context(), "internal error", logger.Err(err))
+		writeProblem(w, r, http.StatusInternalServerError, "internal error")
+	}
+}
@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "orders"
+
+// reasons an ingested message was not saved
+const (
+	ReasonInvalid     = "invalid"
+	ReasonConflict    = "conflict"
+	ReasonUnavailable = "unavailable"
+	ReasonError       = "error"
+)
+
+// cache lookup results
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheError = "error"
+)
+
+// cache tiers
+const (
+	TierMemory = "memory"
+	TierRedis  = "redis"
+)
+
+var (
+	MessagesConsumed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_consumed_total",
+		Help:      "Messages fetched from Kafka.",
+	})
+	MessagesSaved = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_saved_total",
+		Help:      "Messages saved as orders, by result: inserted or duplicate of a stored order.",
+	}, []string{"result"})
+	MessagesFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_failed_total",
+		Help:      "Messages that were not saved, by reason.",
+	}, []string{"reason"})
+	ConsumerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "consumer_lag",
+		Help:      "Messages between the last fetched one and the end of the partition.",
+	}, []string{"partition"})
+
+	SaveDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "save_duration_seconds",
+		Help:      "Time to save orders to the database, by mode: single order or batch.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"mode"})
+	GetDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "get_duration_seconds",
+		Help:      "Time to get an order, by source: cache or db.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"source"})
+	GetNotFound = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "get_not_found_total",
+		Help:      "Requests for orders that do not exist, by what found it out: cache or db.",
+	}, []string{"source"})
+	BloomMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bloom_misses_total",
+		Help:      "Requests for orders missed by the Bloom filter, by result: found (saved by another instance) or not_found.",
+	}, []string{"result"})
+	GetSharedLoads = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "get_shared_loads_total",
+		Help:      "Order reads from the database shared by concurrent requests for the same order, counted per request.",
+	})
+
+	CacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups, by tier: memory or redis, and result: hit, miss or error.",
+	}, []string{"tier", "result"})
+	CacheEvictions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_evictions_total",
+		Help:      "Entries evicted from a cache tier to stay within its size limits.",
+	}, []string{"tier"})
+	CacheEarlyRefreshes = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_early_refreshes_total",
+		Help:      "Requests that triggered a reload of an order close to its cache expiration.",
+	})
+	CacheWarmupDuration = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cache_warmup_duration_seconds",
+		Help:      "Time the last cache warm-up took.",
+	})
+
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests, by route pattern, method and status code.",
+	}, []string{"route", "method", "status"})
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by route pattern and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+)
+
+func Since(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+func SetConsumerLag(partition int, lag int64) {
+	ConsumerLag.WithLabelValues(strconv.Itoa(partition)).Set(float64(max(lag, 0)))
+}
+
+// RegisterDB exports the connection pool stats of db.
+func RegisterDB(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// RegisterLocalCache exports the number of entries and the approximate size of the in-memory cache tier.
+func RegisterLocalCache(entries func() float64, bytes func() float64) {
+	prometheus.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "cache_local_entries",
+			Help:      "Orders in the in-memory cache tier.",
+		}, entries),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "cache_local_bytes",
+			Help:      "Approximate size of the orders in the in-memory cache tier.",
+		}, bytes),
+	)
+}
+
+// RegisterCacheBreaker exports the cache circuit breaker state: 0 closed, 1 open, 2 half-open.
+func RegisterCacheBreaker(state func() float64) {
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "cache_breaker_state",
+		Help:      "Cache circuit breaker state: 0 closed, 1 open, 2 half-open.",
+	}, state))
+}
@@ -0,0 +1,300 @@
+package migrator
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/karambo3a/wbtech_test_task/internal/logger"
+)
+
+const (
+	createMigrationsTableQuery = `CREATE TABLE IF NOT EXISTS schema_migrations
+(
+    version BIGINT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`
+	getAppliedMigrationsQuery = `SELECT version, name, applied_at FROM schema_migrations ORDER BY version`
+	insertMigrationQuery      = `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+	deleteMigrationQuery      = `DELETE FROM schema_migrations WHERE version = $1`
+
+	// session lock, so instances starting at once apply migrations one after another
+	lockQuery   = `SELECT pg_advisory_lock(hashtext('schema_migrations'))`
+	unlockQuery = `SELECT pg_advisory_unlock(hashtext('schema_migrations'))`
+)
+
+var migrationFileRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes a migration known from the files or from schema_migrations.
+// AppliedAt is nil for a pending migration.
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+type appliedMigration struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+	seeds      fs.FS
+}
+
+func NewMigrator(db *sqlx.DB, migrations fs.FS, seeds fs.FS) (*Migrator, error) {
+	loaded, err := LoadMigrations(migrations)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: loaded, seeds: seeds}, nil
+}
+
+// LoadMigrations reads <version>_<name>.up.sql and <version>_<name>.down.sql files from fsys
+// and returns them ordered by version. Every version must have both files.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFileRegexp.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies all pending migrations in version order, each in its own transaction,
+// and returns the number of applied migrations.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		applied, err := getApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			if err := apply(ctx, conn, migration.Up, insertMigrationQuery, migration.Version, migration.Name); err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			slog.InfoContext(ctx, "migration applied", "version", migration.Version, "name", migration.Name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down rolls back up to steps latest applied migrations and returns the number of rolled back ones.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	byVersion := make(map[int64]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	count := 0
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		var applied []appliedMigration
+		if err := conn.SelectContext(ctx, &applied, getAppliedMigrationsQuery); err != nil {
+			return fmt.Errorf("failed to get applied migrations: %w", err)
+		}
+
+		for i := len(applied) - 1; i >= 0 && count < steps; i-- {
+			migration, ok := byVersion[applied[i].Version]
+			if !ok {
+				return fmt.Errorf("failed to roll back migration %d_%s: migration file not found", applied[i].Version, applied[i].Name)
+			}
+			if err := apply(ctx, conn, migration.Down, deleteMigrationQuery, migration.Version); err != nil {
+				return fmt.Errorf("failed to roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			slog.InfoContext(ctx, "migration rolled back", "version", migration.Version, "name", migration.Name)
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Status lists the known migrations and the applied ones missing from the files, ordered by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		applied, err := getApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if a, ok := applied[migration.Version]; ok {
+				status.AppliedAt = &a.AppliedAt
+				delete(applied, migration.Version)
+			}
+			statuses = append(statuses, status)
+		}
+		for _, a := range applied {
+			statuses = append(statuses, Status{Version: a.Version, Name: a.Name, AppliedAt: &a.AppliedAt})
+		}
+		return nil
+	})
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, err
+}
+
+// Seed applies all seed files in name order in a single transaction.
+// Seed files are expected to be safe to run more than once.
+func (m *Migrator) Seed(ctx context.Context) error {
+	names, err := fs.Glob(m.seeds, "*.sql")
+	if err != nil {
+		return fmt.Errorf("failed to read seeds: %w", err)
+	}
+	sort.Strings(names)
+
+	return m.withLock(ctx, func(conn *sqlx.Conn) error {
+		tx, err := conn.BeginTxx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer func() {
+			if err := tx.Rollback(); err != nil {
+				return
+			}
+		}()
+
+		for _, name := range names {
+			data, err := fs.ReadFile(m.seeds, name)
+			if err != nil {
+				return fmt.Errorf("failed to read seed %s: %w", name, err)
+			}
+			if _, err := tx.ExecContext(ctx, string(data)); err != nil {
+				return fmt.Errorf("failed to apply seed %s: %w", name, err)
+			}
+			slog.InfoContext(ctx, "seed applied", "name", name)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil
+	})
+}
+
+// withLock runs fn on a single connection holding the migrations advisory lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, lockQuery); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), unlockQuery); err != nil {
+			slog.ErrorContext(ctx, "failed to unlock migrations", logger.Err(err))
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, createMigrationsTableQuery); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return fn(conn)
+}
+
+func getApplied(ctx context.Context, conn *sqlx.Conn) (map[int64]appliedMigration, error) {
+	var rows []appliedMigration
+	if err := conn.SelectContext(ctx, &rows, getAppliedMigrationsQuery); err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	applied := make(map[int64]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// apply runs the migration script and records the change in schema_migrations in one transaction.
+func apply(ctx context.Context, conn *sqlx.Conn, script string, recordQuery string, recordArgs ...any) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			return
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, recordQuery, recordArgs...); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,10 @@ package model
 
 import "time"
 
+// OrderSchemaVersion is part of the cache keys of orders. Bump it on an incompatible change
+// of the Order JSON, so orders cached in the old format are no longer read and just expire.
+const OrderSchemaVersion = 1
+
 type Order struct {
 	OrderUID          string    `json:"order_uid"`
 	TrackNumber       string    `json:"track_number"`
@@ -55,3 +59,41 @@ type Payment struct {
 	GoodsTotal   int    `json:"goods_total"`
 	CustomFee    int    `json:"custom_fee"`
 }
+
+type IngestFailure struct {
+	ID        int64     `db:"id" json:"id"`
+	Topic     string    `db:"topic" json:"topic"`
+	Partition int       `db:"partition" json:"partition"`
+	Offset    int64     `db:"kafka_offset" json:"offset"`
+	Key       string    `db:"message_key" json:"key"`
+	Payload   string    `db:"payload" json:"payload"`
+	Error     string    `db:"error" json:"error"`
+	Attempts  int       `db:"attempts" json:"attempts"`
+	FailedAt  time.Time `db:"failed_at" json:"failed_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// OrderFilter selects orders for listing, empty fields are not applied.
+// CreatedFrom is inclusive and CreatedTo is exclusive.
+type OrderFilter struct {
+	CustomerID      string
+	TrackNumber     string
+	DeliveryService string
+	Locale          string
+	Currency        string
+	Provider        string
+	CreatedFrom     time.Time
+	CreatedTo       time.Time
+}
+
+// OrderCursor points at the last order of a page, orders are listed by date_created
+// and order_uid, both descending.
+type OrderCursor struct {
+	DateCreated time.Time `json:"date_created"`
+	OrderUID    string    `json:"order_uid"`
+}
+
+type OrderPage struct {
+	Orders     []*Order `json:"orders"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+)
+
+// wrapError marks err with the domain error kind matching its database cause.
+func wrapError(err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, sql.ErrNoRows):
+		return apperror.Wrap(apperror.ErrNotFound, err)
+	case isTransientError(err):
+		return apperror.Wrap(apperror.ErrUnavailable, err)
+	case errors.As(err, &pgErr) && (strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")):
+		// data exception or integrity constraint violation
+		return apperror.Wrap(apperror.ErrInvalidInput, err)
+	default:
+		return err
+	}
+}
+
+// isTransientError reports whether err is caused by a database condition that may go away
+// on its own (lost connection, restart, deadlock) or by the operation running out of time,
+// so the operation is worth retrying.
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if len(pgErr.Code) < 2 {
+			return false
+		}
+		switch pgErr.Code[:2] {
+		// connection exception, transaction rollback, insufficient resources, operator intervention, system error
+		case "08", "40", "53", "57", "58":
+			return true
+		}
+		return false
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || pgconn.SafeToRetry(err) || pgconn.Timeout(err)
+}
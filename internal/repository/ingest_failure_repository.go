@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+)
+
+type IngestFailureRepository struct {
+	db *sqlx.DB
+}
+
+func NewIngestFailureRepository(db *sqlx.DB) *IngestFailureRepository {
+	return &IngestFailureRepository{db: db}
+}
+
+const (
+	ingestFailureColumns = `id, topic, partition, kafka_offset, message_key, payload, error, attempts, failed_at, updated_at`
+
+	insertIngestFailureQuery = `INSERT INTO ingest_failures (topic, partition, kafka_offset, message_key, payload, error)
+							VALUES ($1, $2, $3, $4, $5, $6) RETURNING id;`
+	listIngestFailuresQuery = `SELECT ` + ingestFailureColumns + ` FROM ingest_failures
+							ORDER BY failed_at DESC, id DESC LIMIT $1 OFFSET $2`
+	getIngestFailureQuery           = `SELECT ` + ingestFailureColumns + ` FROM ingest_failures WHERE id = $1`
+	updateIngestFailurePayloadQuery = `UPDATE ingest_failures SET payload = $2, updated_at = now()
+							WHERE id = $1 RETURNING ` + ingestFailureColumns
+	updateIngestFailureErrorQuery = `UPDATE ingest_failures SET error = $2, attempts = attempts + 1, updated_at = now()
+							WHERE id = $1`
+	deleteIngestFailureQuery = `DELETE FROM ingest_failures WHERE id = $1`
+)
+
+func (r *IngestFailureRepository) SaveFailure(ctx context.Context, failure *model.IngestFailure) (int64, error) {
+	var id int64
+	err := r.db.GetContext(ctx, &id, insertIngestFailureQuery,
+		failure.Topic,
+		failure.Partition,
+		failure.Offset,
+		[]byte(failure.Key),
+		[]byte(failure.Payload),
+		failure.Error)
+	if err != nil {
+		return 0, wrapError(fmt.Errorf("failed to insert ingest failure: %w", err))
+	}
+	return id, nil
+}
+
+func (r *IngestFailureRepository) ListFailures(ctx context.Context, limit int, offset int) ([]model.IngestFailure, error) {
+	failures := []model.IngestFailure{}
+	if err := r.db.SelectContext(ctx, &failures, listIngestFailuresQuery, limit, offset); err != nil {
+		return nil, wrapError(fmt.Errorf("failed to get ingest failures: %w", err))
+	}
+	return failures, nil
+}
+
+func (r *IngestFailureRepository) GetFailure(ctx context.Context, id int64) (*model.IngestFailure, error) {
+	var failure model.IngestFailure
+	if err := r.db.GetContext(ctx, &failure, getIngestFailureQuery, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.Wrap(apperror.ErrNotFound, fmt.Errorf("ingest failure %d not found", id))
+		}
+		return nil, wrapError(fmt.Errorf("failed to get ingest failure %d: %w", id, err))
+	}
+	return &failure, nil
+}
+
+func (r *IngestFailureRepository) UpdateFailurePayload(ctx context.Context, id int64, payload string) (*model.IngestFailure, error) {
+	var failure model.IngestFailure
+	if err := r.db.GetContext(ctx, &failure, updateIngestFailurePayloadQuery, id, []byte(payload)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperror.Wrap(apperror.ErrNotFound, fmt.Errorf("ingest failure %d not found", id))
+		}
+		return nil, wrapError(fmt.Errorf("failed to update ingest failure %d: %w", id, err))
+	}
+	return &failure, nil
+}
+
+func (r *IngestFailureRepository) UpdateFailureError(ctx context.Context, id int64, failureErr string) error {
+	if _, err := r.db.ExecContext(ctx, updateIngestFailureErrorQuery, id, failureErr); err != nil {
+		return wrapError(fmt.Errorf("failed to update ingest failure %d: %w", id, err))
+	}
+	return nil
+}
+
+func (r *IngestFailureRepository) DeleteFailure(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, deleteIngestFailureQuery, id)
+	if err != nil {
+		return wrapError(fmt.Errorf("failed to delete ingest failure %d: %w", id, err))
+	}
+	if rows, err := res.RowsAffected(); err == nil && rows == 0 {
+		return apperror.Wrap(apperror.ErrNotFound, fmt.Errorf("ingest failure %d not found", id))
+	}
+	return nil
+}
@@ -1,22 +1,56 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
 	"github.com/karambo3a/wbtech_test_task/internal/model"
 )
 
+type SaveResult int
+
+const (
+	SaveResultFailed SaveResult = iota
+	SaveResultInserted
+	SaveResultDuplicate
+	SaveResultConflict
+)
+
+func (r SaveResult) String() string {
+	switch r {
+	case SaveResultInserted:
+		return "inserted"
+	case SaveResultDuplicate:
+		return "duplicate"
+	case SaveResultConflict:
+		return "conflict"
+	default:
+		return "failed"
+	}
+}
+
+var ErrOrderConflict = fmt.Errorf("order with the same order_uid and different content already exists: %w", apperror.ErrConflict)
+
 type OrderRepository struct {
-	db *sqlx.DB
+	db           *sqlx.DB
+	getOrderStmt *sqlx.Stmt
 }
 
-func NewOrderRepository(db *sqlx.DB) *OrderRepository {
-	return &OrderRepository{db: db}
+func NewOrderRepository(db *sqlx.DB) (*OrderRepository, error) {
+	getOrderStmt, err := db.Preparex(getOrderWithItemsQuery)
+	if err != nil {
+		return nil, wrapError(fmt.Errorf("failed to prepare get order statement: %w", err))
+	}
+	return &OrderRepository{db: db, getOrderStmt: getOrderStmt}, nil
 }
 
 type dbOrder struct {
@@ -85,7 +119,57 @@ const (
         FROM orders o
         LEFT JOIN deliveries d ON o.delivery_id = d.id
         LEFT JOIN payments p ON o.payment_id = p.id`
-	getItemsQuery = `SELECT
+	// items are aggregated into a JSON array, NULL for an order without items
+	getOrderWithItemsQuery = `SELECT
+            o.order_uid,
+            o.track_number,
+            o.entry,
+            o.locale,
+            o.internal_signature,
+            o.customer_id,
+            o.delivery_service,
+            o.shardkey,
+            o.sm_id,
+            o.date_created,
+            o.oof_shard,
+            d.name AS delivery_name,
+            d.phone AS delivery_phone,
+            d.zip AS delivery_zip,
+            d.city AS delivery_city,
+            d.address AS delivery_address,
+            d.region AS delivery_region,
+            d.email AS delivery_email,
+            p.transaction AS payment_transaction,
+            p.request_id AS payment_request_id,
+            p.currency AS payment_currency,
+            p.provider AS payment_provider,
+            p.amount AS payment_amount,
+            p.payment_dt AS payment_payment_dt,
+            p.bank AS payment_bank,
+            p.delivery_cost AS payment_delivery_cost,
+            p.goods_total AS payment_goods_total,
+            p.custom_fee AS payment_custom_fee,
+            (SELECT json_agg(json_build_object(
+                    'chrt_id', i.chrt_id,
+                    'track_number', i.track_number,
+                    'price', i.price,
+                    'rid', i.rid,
+                    'name', i.name,
+                    'sale', i.sale,
+                    'size', i.size,
+                    'total_price', i.total_price,
+                    'nm_id', i.nm_id,
+                    'brand', i.brand,
+                    'status', i.status) ORDER BY oi.id)
+                FROM orders_x_items oi
+                JOIN items i ON i.id = oi.item_id
+                WHERE oi.order_uid = o.order_uid) AS items
+        FROM orders o
+        LEFT JOIN deliveries d ON o.delivery_id = d.id
+        LEFT JOIN payments p ON o.payment_id = p.id
+        WHERE o.order_uid = $1`
+	getItemsByOrderUIDsQuery = `SELECT
+            oi.order_uid,
             i.chrt_id,
             i.track_number,
             i.price,
@@ -97,10 +181,16 @@ const (
             i.nm_id,
             i.brand,
             i.status
-        FROM items i
-		LEFT JOIN orders_x_items oi ON i.id = oi.item_id
-		LEFT JOIN orders o ON o.order_uid = oi.order_uid
-		WHERE o.order_uid = $1`
+        FROM orders_x_items oi
+		JOIN items i ON i.id = oi.item_id
+		WHERE oi.order_uid = ANY($1)
+		ORDER BY oi.id`
+	addOrderAccessesQuery = `INSERT INTO order_access_counts (order_uid, hits)
+            SELECT a.order_uid, a.hits
+            FROM unnest($1::varchar[], $2::bigint[]) AS a(order_uid, hits)
+            JOIN orders o ON o.order_uid = a.order_uid
+            ON CONFLICT (order_uid) DO UPDATE
+            SET hits = order_access_counts.hits + EXCLUDED.hits, last_accessed_at = now()`
 	insertDeliveryQuery = `INSERT INTO deliveries (name, phone, zip, city, address, region, email)
 							VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (name, phone, zip, city, address, region, email) DO NOTHING RETURNING id;`
 	getDeliveryQuery   = `SELECT id FROM deliveries WHERE name=$1 AND phone=$2 AND zip=$3 AND city=$4 AND address=$5 AND region=$6 AND email=$7`
@@ -112,30 +202,78 @@ const (
 						VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id;`
 	insertOrdersItemsQuery = `INSERT INTO orders_x_items (order_uid, item_id)
 							VALUES ($1, $2);`
+
+	lockOrderUIDsQuery      = `SELECT pg_advisory_xact_lock(hashtext(uid)) FROM (SELECT DISTINCT unnest($1::text[]) AS uid ORDER BY uid) AS uids`
+	getStoredOrderUIDsQuery = `SELECT order_uid FROM orders WHERE order_uid = ANY($1)`
+
+	insertDeliveriesBatchQuery = `INSERT INTO deliveries (name, phone, zip, city, address, region, email)
+							SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[], $6::text[], $7::text[])
+							ON CONFLICT (name, phone, zip, city, address, region, email) DO NOTHING;`
+	getDeliveriesBatchQuery = `SELECT d.id, d.name, d.phone, d.zip, d.city, d.address, d.region, d.email
+							FROM deliveries d
+							JOIN unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[], $6::text[], $7::text[])
+								AS t(name, phone, zip, city, address, region, email)
+							ON d.name = t.name AND d.phone = t.phone AND d.zip = t.zip AND d.city = t.city
+								AND d.address = t.address AND d.region = t.region AND d.email = t.email`
+	nextPaymentIDsQuery      = `SELECT nextval(pg_get_serial_sequence('payments', 'id')) FROM generate_series(1, $1)`
+	insertPaymentsBatchQuery = `INSERT INTO payments (id, transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
+							SELECT * FROM unnest($1::int[], $2::text[], $3::text[], $4::text[], $5::text[], $6::int[], $7::bigint[], $8::text[], $9::int[], $10::int[], $11::int[]);`
+	insertOrdersBatchQuery = `INSERT INTO orders (order_uid, track_number, entry, delivery_id, payment_id, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
+							SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::int[], $5::int[], $6::text[], $7::text[], $8::text[], $9::text[], $10::text[], $11::int[], $12::timestamptz[], $13::text[]);`
+	nextItemIDsQuery      = `SELECT nextval(pg_get_serial_sequence('items', 'id')) FROM generate_series(1, $1)`
+	insertItemsBatchQuery = `INSERT INTO items (id, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
+							SELECT * FROM unnest($1::int[], $2::int[], $3::text[], $4::int[], $5::text[], $6::text[], $7::int[], $8::text[], $9::int[], $10::int[], $11::text[], $12::int[]);`
+	insertOrdersItemsBatchQuery = `INSERT INTO orders_x_items (order_uid, item_id)
+							SELECT * FROM unnest($1::text[], $2::int[]);`
 )
 
-func (r *OrderRepository) GetOrder(orderUID string) (*model.Order, error) {
-	var dbOrd dbOrder
+// GetOrder reads the order with its items in a single prepared statement.
+func (r *OrderRepository) GetOrder(ctx context.Context, orderUID string) (*model.Order, error) {
+	var dbOrd dbOrderWithItems
+	if err := r.getOrderStmt.GetContext(ctx, &dbOrd, orderUID); err != nil {
+		return &model.Order{}, getOrderError(orderUID, err)
+	}
 
-	tx, err := r.db.Beginx()
+	order, err := dbOrd.toModel()
 	if err != nil {
-		return &model.Order{}, fmt.Errorf("failed to begin transaction: %w", err)
+		return &model.Order{}, err
 	}
-	defer func() {
-		if err := tx.Rollback(); err != nil {
-			return
-		}
-	}()
+	return order, nil
+}
 
-	err = tx.Get(&dbOrd, getOrderQuery+" WHERE o.order_uid = $1", orderUID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return &model.Order{}, fmt.Errorf("order %s not found: %w", orderUID, err)
-		}
-		return &model.Order{}, fmt.Errorf("failed to get order %s: %w", orderUID, err)
+func getOrder(ctx context.Context, q sqlx.QueryerContext, orderUID string) (*model.Order, error) {
+	var dbOrd dbOrderWithItems
+	if err := sqlx.GetContext(ctx, q, &dbOrd, getOrderWithItemsQuery, orderUID); err != nil {
+		return nil, getOrderError(orderUID, err)
+	}
+	return dbOrd.toModel()
+}
+
+func getOrderError(orderUID string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.Wrap(apperror.ErrNotFound, fmt.Errorf("order %s not found", orderUID))
 	}
+	return wrapError(fmt.Errorf("failed to get order %s: %w", orderUID, err))
+}
+
+type dbOrderWithItems struct {
+	dbOrder
+	Items []byte `db:"items"`
+}
 
-	order := model.Order{
+func (dbOrd dbOrderWithItems) toModel() (*model.Order, error) {
+	order := dbOrd.dbOrder.toModel()
+	if dbOrd.Items == nil {
+		return order, nil
+	}
+	if err := json.Unmarshal(dbOrd.Items, &order.Items); err != nil {
+		return nil, fmt.Errorf("failed to decode items of order %s: %w", dbOrd.OrderUID, err)
+	}
+	return order, nil
+}
+
+func (dbOrd dbOrder) toModel() *model.Order {
+	return &model.Order{
 		OrderUID:          dbOrd.OrderUID,
 		TrackNumber:       dbOrd.TrackNumber,
 		Entry:             dbOrd.Entry,
@@ -169,24 +307,15 @@ func (r *OrderRepository) GetOrder(orderUID string) (*model.Order, error) {
 			CustomFee:    dbOrd.PaymentCustomFee,
 		},
 	}
-
-	var items []model.Item
-	err = tx.Select(&items, getItemsQuery, orderUID)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return &model.Order{}, fmt.Errorf("failed to get items: %w", err)
-	}
-	order.Items = items
-
-	if err := tx.Commit(); err != nil {
-		return &model.Order{}, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-	return &order, nil
 }
 
-func (r *OrderRepository) SaveOrder(order *model.Order) error {
-	tx, err := r.db.Beginx()
+// SaveOrder inserts the order unless an order with the same order_uid is already stored.
+// A redelivered identical order is reported as SaveResultDuplicate, a different one
+// as SaveResultConflict; in both cases nothing is written.
+func (r *OrderRepository) SaveOrder(ctx context.Context, order *model.Order) (SaveResult, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return SaveResultFailed, wrapError(fmt.Errorf("failed to begin transaction: %w", err))
 	}
 	defer func() {
 		if err := tx.Rollback(); err != nil {
@@ -194,8 +323,20 @@ func (r *OrderRepository) SaveOrder(order *model.Order) error {
 		}
 	}()
 
+	if _, err := tx.ExecContext(ctx, lockOrderUIDsQuery, []string{order.OrderUID}); err != nil {
+		return SaveResultFailed, wrapError(fmt.Errorf("failed to lock order %s: %w", order.OrderUID, err))
+	}
+
+	stored, err := getOrder(ctx, tx, order.OrderUID)
+	if err == nil {
+		return compareOrders(stored, order), nil
+	}
+	if !errors.Is(err, apperror.ErrNotFound) {
+		return SaveResultFailed, err
+	}
+
 	var deliveryID int64
-	err = tx.Get(&deliveryID, insertDeliveryQuery,
+	err = tx.GetContext(ctx, &deliveryID, insertDeliveryQuery,
 		order.Delivery.Name,
 		order.Delivery.Phone,
 		order.Delivery.Zip,
@@ -204,7 +345,7 @@ func (r *OrderRepository) SaveOrder(order *model.Order) error {
 		order.Delivery.Region,
 		order.Delivery.Email)
 	if err != nil {
-		err = tx.Get(&deliveryID, getDeliveryQuery,
+		err = tx.GetContext(ctx, &deliveryID, getDeliveryQuery,
 			order.Delivery.Name,
 			order.Delivery.Phone,
 			order.Delivery.Zip,
@@ -213,14 +354,14 @@ func (r *OrderRepository) SaveOrder(order *model.Order) error {
 			order.Delivery.Region,
 			order.Delivery.Email)
 		if err != nil {
-			return fmt.Errorf("failed to insert new delivery: %w", err)
+			return SaveResultFailed, wrapError(fmt.Errorf("failed to insert new delivery: %w", err))
 		}
 	} else {
-		log.Println("new delivery inserted")
+		slog.DebugContext(ctx, "new delivery inserted", "order_uid", order.OrderUID)
 	}
 
 	var paymentID int
-	err = tx.Get(&paymentID, insertPaymentQuery,
+	err = tx.GetContext(ctx, &paymentID, insertPaymentQuery,
 		order.Payment.Transaction,
 		order.Payment.RequestID,
 		order.Payment.Currency,
@@ -233,13 +374,12 @@ func (r *OrderRepository) SaveOrder(order *model.Order) error {
 		order.Payment.CustomFee)
 
 	if err != nil {
-		return fmt.Errorf("failed to insert new payment: %w", err)
+		return SaveResultFailed, wrapError(fmt.Errorf("failed to insert new payment: %w", err))
 	}
 
-	log.Printf("delivery_id=%d", deliveryID)
-	log.Printf("payment_id=%d", paymentID)
+	slog.DebugContext(ctx, "delivery and payment saved", "order_uid", order.OrderUID, "delivery_id", deliveryID, "payment_id", paymentID)
 
-	_, err = tx.Exec(insertOrderQuery,
+	_, err = tx.ExecContext(ctx, insertOrderQuery,
 		order.OrderUID,
 		order.TrackNumber,
 		order.Entry,
@@ -255,12 +395,12 @@ func (r *OrderRepository) SaveOrder(order *model.Order) error {
 		order.OofShard)
 
 	if err != nil {
-		return fmt.Errorf("failed to insert new order: %w", err)
+		return SaveResultFailed, wrapError(fmt.Errorf("failed to insert new order: %w", err))
 	}
 
 	for _, item := range order.Items {
 		var itemID int64
-		err := tx.Get(&itemID, insertItemQuery,
+		err := tx.GetContext(ctx, &itemID, insertItemQuery,
 			item.ChrtID,
 			item.TrackNumber,
 			item.Price,
@@ -274,25 +414,33 @@ func (r *OrderRepository) SaveOrder(order *model.Order) error {
 			item.Status,
 		)
 		if err != nil {
-			return fmt.Errorf("failed to insert new item: %w", err)
+			return SaveResultFailed, wrapError(fmt.Errorf("failed to insert new item: %w", err))
 		}
 
-		_, err = tx.Exec(insertOrdersItemsQuery, order.OrderUID, itemID)
+		_, err = tx.ExecContext(ctx, insertOrdersItemsQuery, order.OrderUID, itemID)
 		if err != nil {
-			return fmt.Errorf("failed to insert new item: %w", err)
+			return SaveResultFailed, wrapError(fmt.Errorf("failed to insert new item: %w", err))
 		}
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return SaveResultFailed, wrapError(fmt.Errorf("failed to commit transaction: %w", err))
 	}
-	return nil
+	return SaveResultInserted, nil
 }
 
-func (r *OrderRepository) GetAllOrders(limit int64) ([]*model.Order, error) {
-	tx, err := r.db.Beginx()
+// SaveOrders stores all orders in one transaction with one multi-row statement per table
+// and returns the outcome for each order, like SaveOrder does.
+// Serial ids of payments and items are reserved up front, so rows can be linked
+// without relying on the order of RETURNING results.
+func (r *OrderRepository) SaveOrders(ctx context.Context, orders []*model.Order) ([]SaveResult, error) {
+	if len(orders) == 0 {
+		return nil, nil
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, wrapError(fmt.Errorf("failed to begin transaction: %w", err))
 	}
 	defer func() {
 		if err := tx.Rollback(); err != nil {
@@ -300,63 +448,394 @@ func (r *OrderRepository) GetAllOrders(limit int64) ([]*model.Order, error) {
 		}
 	}()
 
-	var dbOrds []dbOrder
-	err = tx.Select(&dbOrds, getOrderQuery+" ORDER BY o.date_created DESC LIMIT $1", limit)
+	results, newOrders, err := filterStoredOrders(ctx, tx, orders)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get orders: %w", err)
+		return nil, err
+	}
+	if len(newOrders) == 0 {
+		return results, nil
 	}
+	orders = newOrders
 
-	var orders []*model.Order
-	for _, dbOrd := range dbOrds {
-		order := &model.Order{
-			OrderUID:          dbOrd.OrderUID,
-			TrackNumber:       dbOrd.TrackNumber,
-			Entry:             dbOrd.Entry,
-			Locale:            dbOrd.Locale,
-			InternalSignature: dbOrd.InternalSignature,
-			CustomerID:        dbOrd.CustomerID,
-			DeliveryService:   dbOrd.DeliveryService,
-			Shardkey:          dbOrd.Shardkey,
-			SmID:              dbOrd.SmID,
-			DateCreated:       dbOrd.DateCreated,
-			OofShard:          dbOrd.OofShard,
-			Delivery: model.Delivery{
-				Name:    dbOrd.DeliveryName,
-				Phone:   dbOrd.DeliveryPhone,
-				Zip:     dbOrd.DeliveryZip,
-				City:    dbOrd.DeliveryCity,
-				Address: dbOrd.DeliveryAddress,
-				Region:  dbOrd.DeliveryRegion,
-				Email:   dbOrd.DeliveryEmail,
-			},
-			Payment: model.Payment{
-				Transaction:  dbOrd.PaymentTransaction,
-				RequestID:    dbOrd.PaymentRequestID,
-				Currency:     dbOrd.PaymentCurrency,
-				Provider:     dbOrd.PaymentProvider,
-				Amount:       dbOrd.PaymentAmount,
-				PaymentDt:    dbOrd.PaymentPaymentDt,
-				Bank:         dbOrd.PaymentBank,
-				DeliveryCost: dbOrd.PaymentDeliveryCost,
-				GoodsTotal:   dbOrd.PaymentGoodsTotal,
-				CustomFee:    dbOrd.PaymentCustomFee,
-			},
+	deliveryIDs, err := saveDeliveries(ctx, tx, orders)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentIDs, err := savePayments(ctx, tx, orders)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		uids, trackNumbers, entries, locales, signatures, customerIDs []string
+		deliveryServices, shardkeys, oofShards                        []string
+		orderDeliveryIDs, orderPaymentIDs, smIDs                      []int64
+		datesCreated                                                  []time.Time
+	)
+	for i, order := range orders {
+		uids = append(uids, order.OrderUID)
+		trackNumbers = append(trackNumbers, order.TrackNumber)
+		entries = append(entries, order.Entry)
+		orderDeliveryIDs = append(orderDeliveryIDs, deliveryIDs[order.Delivery])
+		orderPaymentIDs = append(orderPaymentIDs, paymentIDs[i])
+		locales = append(locales, order.Locale)
+		signatures = append(signatures, order.InternalSignature)
+		customerIDs = append(customerIDs, order.CustomerID)
+		deliveryServices = append(deliveryServices, order.DeliveryService)
+		shardkeys = append(shardkeys, order.Shardkey)
+		smIDs = append(smIDs, int64(order.SmID))
+		datesCreated = append(datesCreated, order.DateCreated)
+		oofShards = append(oofShards, order.OofShard)
+	}
+
+	_, err = tx.ExecContext(ctx, insertOrdersBatchQuery, uids, trackNumbers, entries, orderDeliveryIDs, orderPaymentIDs,
+		locales, signatures, customerIDs, deliveryServices, shardkeys, smIDs, datesCreated, oofShards)
+	if err != nil {
+		return nil, wrapError(fmt.Errorf("failed to insert new orders: %w", err))
+	}
+
+	if err := saveItems(ctx, tx, orders); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, wrapError(fmt.Errorf("failed to commit transaction: %w", err))
+	}
+	return results, nil
+}
+
+// filterStoredOrders locks the order_uids of the batch and returns the orders that are not stored yet.
+// Orders already stored, or repeated within the batch, are compared with the first copy.
+func filterStoredOrders(ctx context.Context, tx *sqlx.Tx, orders []*model.Order) ([]SaveResult, []*model.Order, error) {
+	uids := make([]string, 0, len(orders))
+	for _, order := range orders {
+		uids = append(uids, order.OrderUID)
+	}
+
+	if _, err := tx.ExecContext(ctx, lockOrderUIDsQuery, uids); err != nil {
+		return nil, nil, wrapError(fmt.Errorf("failed to lock orders: %w", err))
+	}
+
+	var storedUIDs []string
+	if err := tx.SelectContext(ctx, &storedUIDs, getStoredOrderUIDsQuery, uids); err != nil {
+		return nil, nil, wrapError(fmt.Errorf("failed to get stored orders: %w", err))
+	}
+
+	seen := make(map[string]*model.Order, len(orders))
+	for _, uid := range storedUIDs {
+		stored, err := getOrder(ctx, tx, uid)
+		if err != nil {
+			return nil, nil, err
+		}
+		seen[uid] = stored
+	}
+
+	results := make([]SaveResult, len(orders))
+	newOrders := make([]*model.Order, 0, len(orders))
+	for i, order := range orders {
+		if first, ok := seen[order.OrderUID]; ok {
+			results[i] = compareOrders(first, order)
+			continue
+		}
+		seen[order.OrderUID] = order
+		newOrders = append(newOrders, order)
+		results[i] = SaveResultInserted
+	}
+	return results, newOrders, nil
+}
+
+func compareOrders(stored *model.Order, order *model.Order) SaveResult {
+	if sameOrder(stored, order) {
+		return SaveResultDuplicate
+	}
+	return SaveResultConflict
+}
+
+// sameOrder compares orders as Postgres stores them: timestamps in UTC with microsecond precision.
+func sameOrder(a *model.Order, b *model.Order) bool {
+	normalize := func(order *model.Order) model.Order {
+		normalized := *order
+		normalized.DateCreated = order.DateCreated.UTC().Truncate(time.Microsecond)
+		if len(normalized.Items) == 0 {
+			normalized.Items = nil
+		}
+		return normalized
+	}
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func saveDeliveries(ctx context.Context, tx *sqlx.Tx, orders []*model.Order) (map[model.Delivery]int64, error) {
+	var names, phones, zips, cities, addresses, regions, emails []string
+	for _, order := range orders {
+		names = append(names, order.Delivery.Name)
+		phones = append(phones, order.Delivery.Phone)
+		zips = append(zips, order.Delivery.Zip)
+		cities = append(cities, order.Delivery.City)
+		addresses = append(addresses, order.Delivery.Address)
+		regions = append(regions, order.Delivery.Region)
+		emails = append(emails, order.Delivery.Email)
+	}
+
+	_, err := tx.ExecContext(ctx, insertDeliveriesBatchQuery, names, phones, zips, cities, addresses, regions, emails)
+	if err != nil {
+		return nil, wrapError(fmt.Errorf("failed to insert new deliveries: %w", err))
+	}
+
+	var rows []struct {
+		ID int64 `db:"id"`
+		model.Delivery
+	}
+	err = tx.SelectContext(ctx, &rows, getDeliveriesBatchQuery, names, phones, zips, cities, addresses, regions, emails)
+	if err != nil {
+		return nil, wrapError(fmt.Errorf("failed to get deliveries: %w", err))
+	}
+
+	deliveryIDs := make(map[model.Delivery]int64, len(rows))
+	for _, row := range rows {
+		deliveryIDs[row.Delivery] = row.ID
+	}
+	return deliveryIDs, nil
+}
+
+func savePayments(ctx context.Context, tx *sqlx.Tx, orders []*model.Order) ([]int64, error) {
+	var ids []int64
+	if err := tx.SelectContext(ctx, &ids, nextPaymentIDsQuery, len(orders)); err != nil {
+		return nil, wrapError(fmt.Errorf("failed to reserve payment ids: %w", err))
+	}
+
+	var (
+		transactions, requestIDs, currencies, providers, banks []string
+		amounts, deliveryCosts, goodsTotals, customFees        []int64
+		paymentDts                                             []int64
+	)
+	for _, order := range orders {
+		transactions = append(transactions, order.Payment.Transaction)
+		requestIDs = append(requestIDs, order.Payment.RequestID)
+		currencies = append(currencies, order.Payment.Currency)
+		providers = append(providers, order.Payment.Provider)
+		amounts = append(amounts, int64(order.Payment.Amount))
+		paymentDts = append(paymentDts, order.Payment.PaymentDt)
+		banks = append(banks, order.Payment.Bank)
+		deliveryCosts = append(deliveryCosts, int64(order.Payment.DeliveryCost))
+		goodsTotals = append(goodsTotals, int64(order.Payment.GoodsTotal))
+		customFees = append(customFees, int64(order.Payment.CustomFee))
+	}
+
+	_, err := tx.ExecContext(ctx, insertPaymentsBatchQuery, ids, transactions, requestIDs, currencies, providers,
+		amounts, paymentDts, banks, deliveryCosts, goodsTotals, customFees)
+	if err != nil {
+		return nil, wrapError(fmt.Errorf("failed to insert new payments: %w", err))
+	}
+	return ids, nil
+}
+
+func saveItems(ctx context.Context, tx *sqlx.Tx, orders []*model.Order) error {
+	count := 0
+	for _, order := range orders {
+		count += len(order.Items)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	var ids []int64
+	if err := tx.SelectContext(ctx, &ids, nextItemIDsQuery, count); err != nil {
+		return wrapError(fmt.Errorf("failed to reserve item ids: %w", err))
+	}
+
+	var (
+		chrtIDs, prices, sales, totalPrices, nmIDs, statuses []int64
+		trackNumbers, rids, names, sizes, brands             []string
+		orderUIDs                                            []string
+	)
+	for _, order := range orders {
+		for _, item := range order.Items {
+			chrtIDs = append(chrtIDs, int64(item.ChrtID))
+			trackNumbers = append(trackNumbers, item.TrackNumber)
+			prices = append(prices, int64(item.Price))
+			rids = append(rids, item.Rid)
+			names = append(names, item.Name)
+			sales = append(sales, int64(item.Sale))
+			sizes = append(sizes, item.Size)
+			totalPrices = append(totalPrices, int64(item.TotalPrice))
+			nmIDs = append(nmIDs, int64(item.NmID))
+			brands = append(brands, item.Brand)
+			statuses = append(statuses, int64(item.Status))
+			orderUIDs = append(orderUIDs, order.OrderUID)
+		}
+	}
+
+	_, err := tx.ExecContext(ctx, insertItemsBatchQuery, ids, chrtIDs, trackNumbers, prices, rids, names,
+		sales, sizes, totalPrices, nmIDs, brands, statuses)
+	if err != nil {
+		return wrapError(fmt.Errorf("failed to insert new items: %w", err))
+	}
+
+	if _, err := tx.ExecContext(ctx, insertOrdersItemsBatchQuery, orderUIDs, ids); err != nil {
+		return wrapError(fmt.Errorf("failed to insert new items: %w", err))
+	}
+	return nil
+}
+
+// GetRecentOrders returns a page of orders, newest first.
+func (r *OrderRepository) GetRecentOrders(ctx context.Context, offset int64, limit int64) ([]*model.Order, error) {
+	return r.selectOrders(ctx, getOrderQuery+" ORDER BY o.date_created DESC, o.order_uid DESC OFFSET $1 LIMIT $2", offset, limit)
+}
+
+// GetMostAccessedOrders returns a page of orders that were read at least once, most read first.
+func (r *OrderRepository) GetMostAccessedOrders(ctx context.Context, offset int64, limit int64) ([]*model.Order, error) {
+	return r.selectOrders(ctx, getOrderQuery+`
+        JOIN order_access_counts a ON a.order_uid = o.order_uid
+        ORDER BY a.hits DESC, o.order_uid OFFSET $1 LIMIT $2`, offset, limit)
+}
+
+// AddOrderAccesses adds hits to the read counters of orders, unknown order UIDs are skipped.
+func (r *OrderRepository) AddOrderAccesses(ctx context.Context, hits map[string]int64) error {
+	if len(hits) == 0 {
+		return nil
+	}
+
+	orderUIDs := make([]string, 0, len(hits))
+	counts := make([]int64, 0, len(hits))
+	for orderUID, count := range hits {
+		orderUIDs = append(orderUIDs, orderUID)
+		counts = append(counts, count)
+	}
+
+	_, err := r.db.ExecContext(ctx, addOrderAccessesQuery, orderUIDs, counts)
+	if err != nil {
+		return wrapError(fmt.Errorf("failed to add order accesses: %w", err))
+	}
+	return nil
+}
+
+// ForEachOrderUID calls fn with the UID of every stored order. Rows are streamed, so all UIDs are never held in memory at once.
+func (r *OrderRepository) ForEachOrderUID(ctx context.Context, fn func(orderUID string)) error {
+	rows, err := r.db.QueryContext(ctx, `SELECT order_uid FROM orders`)
+	if err != nil {
+		return wrapError(fmt.Errorf("failed to get order uids: %w", err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderUID string
+		if err := rows.Scan(&orderUID); err != nil {
+			return wrapError(fmt.Errorf("failed to scan order uid: %w", err))
 		}
+		fn(orderUID)
+	}
+	if err := rows.Err(); err != nil {
+		return wrapError(fmt.Errorf("failed to get order uids: %w", err))
+	}
+	return nil
+}
+
+// ListOrders returns up to limit orders matching filter, newest first.
+// With a non-nil after only orders that go after the cursor are returned.
+func (r *OrderRepository) ListOrders(ctx context.Context, filter model.OrderFilter, after *model.OrderCursor, limit int) ([]*model.Order, error) {
+	var conditions []string
+	var args []any
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.CustomerID != "" {
+		where("o.customer_id = $%d", filter.CustomerID)
+	}
+	if filter.TrackNumber != "" {
+		where("o.track_number = $%d", filter.TrackNumber)
+	}
+	if filter.DeliveryService != "" {
+		where("o.delivery_service = $%d", filter.DeliveryService)
+	}
+	if filter.Locale != "" {
+		where("o.locale = $%d", filter.Locale)
+	}
+	if filter.Currency != "" {
+		where("p.currency = $%d", filter.Currency)
+	}
+	if filter.Provider != "" {
+		where("p.provider = $%d", filter.Provider)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		where("o.date_created >= $%d", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		where("o.date_created < $%d", filter.CreatedTo)
+	}
+	if after != nil {
+		args = append(args, after.DateCreated, after.OrderUID)
+		conditions = append(conditions, fmt.Sprintf("(o.date_created, o.order_uid) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	query := getOrderQuery
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY o.date_created DESC, o.order_uid DESC LIMIT $%d", len(args))
+
+	return r.selectOrders(ctx, query, args...)
+}
 
-		var items []model.Item
-		err = tx.Select(&items,
-			getItemsQuery,
-			dbOrd.OrderUID)
-		if err != nil && !errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("failed to get items for order %s: %w", dbOrd.OrderUID, err)
+func (r *OrderRepository) selectOrders(ctx context.Context, query string, args ...any) ([]*model.Order, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, wrapError(fmt.Errorf("failed to begin transaction: %w", err))
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			return
 		}
-		order.Items = items
+	}()
+
+	var dbOrds []dbOrder
+	err = tx.SelectContext(ctx, &dbOrds, query, args...)
+	if err != nil {
+		return nil, wrapError(fmt.Errorf("failed to get orders: %w", err))
+	}
 
-		orders = append(orders, order)
+	orders := make([]*model.Order, 0, len(dbOrds))
+	for _, dbOrd := range dbOrds {
+		orders = append(orders, dbOrd.toModel())
+	}
+	if err := loadItems(ctx, tx, orders); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, wrapError(fmt.Errorf("failed to commit transaction: %w", err))
 	}
 	return orders, nil
 }
+
+type dbOrderItem struct {
+	OrderUID string `db:"order_uid"`
+	model.Item
+}
+
+// loadItems fills items of all orders with a single query.
+func loadItems(ctx context.Context, q sqlx.QueryerContext, orders []*model.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	byUID := make(map[string]*model.Order, len(orders))
+	orderUIDs := make([]string, len(orders))
+	for i, order := range orders {
+		byUID[order.OrderUID] = order
+		orderUIDs[i] = order.OrderUID
+	}
+
+	var items []dbOrderItem
+	if err := sqlx.SelectContext(ctx, q, &items, getItemsByOrderUIDsQuery, orderUIDs); err != nil {
+		return wrapError(fmt.Errorf("failed to get items: %w", err))
+	}
+	for _, item := range items {
+		order := byUID[item.OrderUID]
+		order.Items = append(order.Items, item.Item)
+	}
+	return nil
+}
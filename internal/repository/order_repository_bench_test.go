@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+	"github.com/karambo3a/wbtech_test_task/internal/config"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+)
+
+const (
+	benchOrders        = 100
+	benchItemsPerOrder = 3
+)
+
+// perOrderItemsQuery is the items query of a single order that GetOrder and selectOrders used to run.
+const perOrderItemsQuery = `SELECT
+            i.chrt_id,
+            i.track_number,
+            i.price,
+            i.rid,
+            i.name,
+            i.sale,
+            i.size,
+            i.total_price,
+            i.nm_id,
+            i.brand,
+            i.status
+        FROM items i
+		LEFT JOIN orders_x_items oi ON i.id = oi.item_id
+		LEFT JOIN orders o ON o.order_uid = oi.order_uid
+		WHERE o.order_uid = $1
+		ORDER BY oi.id`
+
+// openBenchDB connects to the database from the service config, benchmarks are skipped without DATABASE_HOST.
+func openBenchDB(b *testing.B) *sqlx.DB {
+	b.Helper()
+	if os.Getenv("DATABASE_HOST") == "" {
+		b.Skip("DATABASE_HOST is not set")
+	}
+
+	cfg, _, err := config.Load(nil)
+	if err != nil {
+		b.Fatalf("failed to load config: %v", err)
+	}
+	db, err := NewPostgresDB(cfg.Database)
+	if err != nil {
+		b.Fatalf("failed to connect to db: %v", err)
+	}
+	b.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func newBenchRepository(b *testing.B, db *sqlx.DB) *OrderRepository {
+	b.Helper()
+	repo, err := NewOrderRepository(db)
+	if err != nil {
+		b.Fatalf("failed to create repository: %v", err)
+	}
+	return repo
+}
+
+func newBenchOrder(orderUID string, customerID string, dateCreated time.Time) *model.Order {
+	item := model.Item{
+		ChrtID:      9934930,
+		TrackNumber: "WBILMTESTTRACK",
+		Price:       453,
+		Rid:         "ab4219087a764ae0btest",
+		Name:        "Mascaras",
+		Sale:        30,
+		Size:        "0",
+		TotalPrice:  317,
+		NmID:        2389212,
+		Brand:       "Vivienne Sabo",
+		Status:      202,
+	}
+	order := &model.Order{
+		OrderUID:        orderUID,
+		TrackNumber:     "WBILMTESTTRACK",
+		Entry:           "WBIL",
+		Locale:          "en",
+		CustomerID:      customerID,
+		DeliveryService: "meest",
+		Shardkey:        "9",
+		SmID:            99,
+		DateCreated:     dateCreated,
+		OofShard:        "1",
+		Delivery: model.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: model.Payment{
+			Transaction:  orderUID,
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1500 + benchItemsPerOrder*item.TotalPrice,
+			PaymentDt:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   benchItemsPerOrder * item.TotalPrice,
+		},
+	}
+	for range benchItemsPerOrder {
+		order.Items = append(order.Items, item)
+	}
+	return order
+}
+
+// seedBenchOrders saves benchOrders orders with customer_id set to a unique value and removes them after the benchmark.
+func seedBenchOrders(b *testing.B, db *sqlx.DB) string {
+	b.Helper()
+	customerID := fmt.Sprintf("bench_%d", time.Now().UnixNano())
+
+	start := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+	orders := make([]*model.Order, benchOrders)
+	for i := range orders {
+		orders[i] = newBenchOrder(fmt.Sprintf("%s_%d", customerID, i), customerID, start.Add(time.Duration(i)*time.Minute))
+	}
+
+	if _, err := newBenchRepository(b, db).SaveOrders(context.Background(), orders); err != nil {
+		b.Fatalf("failed to seed orders: %v", err)
+	}
+	b.Cleanup(func() {
+		// orders and orders_x_items are removed by cascade
+		_, _ = db.Exec(`DELETE FROM payments WHERE id IN (SELECT payment_id FROM orders WHERE customer_id = $1)`, customerID)
+		_, _ = db.Exec(`DELETE FROM items WHERE id NOT IN (SELECT item_id FROM orders_x_items)`)
+	})
+	return customerID
+}
+
+// BenchmarkLoadItems compares the query per order that selectOrders used to run for items
+// with loadItems reading the items of the whole page at once.
+func BenchmarkLoadItems(b *testing.B) {
+	db := openBenchDB(b)
+	customerID := seedBenchOrders(b, db)
+	ctx := context.Background()
+
+	var dbOrds []dbOrder
+	if err := db.Select(&dbOrds, getOrderQuery+" WHERE o.customer_id = $1", customerID); err != nil {
+		b.Fatalf("failed to get orders: %v", err)
+	}
+
+	b.Run("per order", func(b *testing.B) {
+		for range b.N {
+			tx, err := db.BeginTxx(ctx, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			orders := make([]*model.Order, 0, len(dbOrds))
+			for _, dbOrd := range dbOrds {
+				order := dbOrd.toModel()
+				var items []model.Item
+				err = tx.Select(&items, perOrderItemsQuery, dbOrd.OrderUID)
+				if err != nil && !errors.Is(err, sql.ErrNoRows) {
+					b.Fatal(err)
+				}
+				order.Items = items
+				orders = append(orders, order)
+			}
+			if err := tx.Commit(); err != nil {
+				b.Fatal(err)
+			}
+			if len(orders[0].Items) != benchItemsPerOrder {
+				b.Fatalf("got %d items", len(orders[0].Items))
+			}
+		}
+	})
+
+	b.Run("whole page", func(b *testing.B) {
+		for range b.N {
+			tx, err := db.BeginTxx(ctx, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			orders := make([]*model.Order, 0, len(dbOrds))
+			for _, dbOrd := range dbOrds {
+				orders = append(orders, dbOrd.toModel())
+			}
+			if err := loadItems(ctx, tx, orders); err != nil {
+				b.Fatal(err)
+			}
+			if err := tx.Commit(); err != nil {
+				b.Fatal(err)
+			}
+			if len(orders[0].Items) != benchItemsPerOrder {
+				b.Fatalf("got %d items", len(orders[0].Items))
+			}
+		}
+	})
+}
+
+func BenchmarkListOrders(b *testing.B) {
+	db := openBenchDB(b)
+	customerID := seedBenchOrders(b, db)
+	repo := newBenchRepository(b, db)
+
+	b.ResetTimer()
+	for range b.N {
+		orders, err := repo.ListOrders(context.Background(), model.OrderFilter{CustomerID: customerID}, nil, benchOrders)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(orders) != benchOrders || len(orders[0].Items) != benchItemsPerOrder {
+			b.Fatalf("got %d orders", len(orders))
+		}
+	}
+}
+
+// BenchmarkGetOrder compares the transaction GetOrder used to run, with separate queries
+// for the order and its items, against its single prepared statement.
+func BenchmarkGetOrder(b *testing.B) {
+	db := openBenchDB(b)
+	customerID := seedBenchOrders(b, db)
+	repo := newBenchRepository(b, db)
+	orderUID := customerID + "_0"
+
+	b.Run("transaction", func(b *testing.B) {
+		for range b.N {
+			tx, err := db.Beginx()
+			if err != nil {
+				b.Fatal(err)
+			}
+			var dbOrd dbOrder
+			if err := tx.Get(&dbOrd, getOrderQuery+" WHERE o.order_uid = $1", orderUID); err != nil {
+				b.Fatal(err)
+			}
+			order := dbOrd.toModel()
+			var items []model.Item
+			err = tx.Select(&items, perOrderItemsQuery, orderUID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				b.Fatal(err)
+			}
+			order.Items = items
+			if err := tx.Commit(); err != nil {
+				b.Fatal(err)
+			}
+			if len(order.Items) != benchItemsPerOrder {
+				b.Fatalf("got %d items", len(order.Items))
+			}
+		}
+	})
+
+	b.Run("single statement", func(b *testing.B) {
+		for range b.N {
+			order, err := repo.GetOrder(context.Background(), orderUID)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(order.Items) != benchItemsPerOrder {
+				b.Fatalf("got %d items", len(order.Items))
+			}
+		}
+	})
+}
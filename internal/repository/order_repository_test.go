@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSameOrder(t *testing.T) {
+	newOrder := func(dateCreated time.Time) *model.Order {
+		return &model.Order{
+			OrderUID:    "order_uid1",
+			TrackNumber: "WBILMTESTTRACK",
+			DateCreated: dateCreated,
+			Delivery:    model.Delivery{Name: "Test Testov"},
+			Payment:     model.Payment{Transaction: "order_uid1", Amount: 1817},
+			Items:       []model.Item{{ChrtID: 9934930, Price: 453}},
+		}
+	}
+	received := time.Date(2021, 11, 26, 6, 22, 19, 123456789, time.FixedZone("MSK", 3*60*60))
+	// Postgres keeps microseconds and the order is read back in UTC
+	stored := time.Date(2021, 11, 26, 3, 22, 19, 123456000, time.UTC)
+
+	tests := []struct {
+		name string
+		a, b *model.Order
+		same bool
+	}{
+		{name: "redelivery with nanoseconds", a: newOrder(received), b: newOrder(stored), same: true},
+		{name: "different microsecond", a: newOrder(received), b: newOrder(stored.Add(time.Microsecond)), same: false},
+		{name: "no items", a: &model.Order{OrderUID: "order_uid1"}, b: &model.Order{OrderUID: "order_uid1", Items: []model.Item{}}, same: true},
+		{
+			name: "different payment",
+			a:    newOrder(stored),
+			b: func() *model.Order {
+				order := newOrder(stored)
+				order.Payment.Amount++
+				return order
+			}(),
+			same: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.same, sameOrder(tt.a, tt.b))
+		})
+	}
+}
@@ -2,19 +2,20 @@ package repository
 
 import (
 	"fmt"
-	"os"
+	"net/url"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/karambo3a/wbtech_test_task/internal/config"
 )
 
-func NewPostgresDB() (*sqlx.DB, error) {
+func NewPostgresDB(cfg config.DatabaseConfig) (*sqlx.DB, error) {
 	dataSourceName := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		os.Getenv("DATABASE_USER"),
-		os.Getenv("DATABASE_PASSWORD"),
-		os.Getenv("DATABASE_HOST"),
-		os.Getenv("DATABASE_PORT"),
-		os.Getenv("DATABASE_NAME"),
-		"disable")
+		url.PathEscape(cfg.User),
+		url.PathEscape(cfg.Password),
+		cfg.Host,
+		cfg.Port,
+		cfg.Name,
+		url.QueryEscape(cfg.SSLMode))
 
 	db, err := sqlx.Connect("pgx", dataSourceName)
 	if err != nil {
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/karambo3a/wbtech_test_task/internal/model"
 )
@@ -8,17 +10,37 @@ import (
 //go:generate mockgen -source=repository.go -destination=../../test/mocks/repository_mock.go
 
 type OrderRepositoryInterface interface {
-	GetOrder(orderUID string) (*model.Order, error)
-	SaveOrder(order *model.Order) error
-	GetAllOrders(limit int64) ([]*model.Order, error)
+	GetOrder(ctx context.Context, orderUID string) (*model.Order, error)
+	SaveOrder(ctx context.Context, order *model.Order) (SaveResult, error)
+	SaveOrders(ctx context.Context, orders []*model.Order) ([]SaveResult, error)
+	GetRecentOrders(ctx context.Context, offset int64, limit int64) ([]*model.Order, error)
+	GetMostAccessedOrders(ctx context.Context, offset int64, limit int64) ([]*model.Order, error)
+	AddOrderAccesses(ctx context.Context, hits map[string]int64) error
+	ForEachOrderUID(ctx context.Context, fn func(orderUID string)) error
+	ListOrders(ctx context.Context, filter model.OrderFilter, after *model.OrderCursor, limit int) ([]*model.Order, error)
+}
+
+type IngestFailureRepositoryInterface interface {
+	SaveFailure(ctx context.Context, failure *model.IngestFailure) (int64, error)
+	ListFailures(ctx context.Context, limit int, offset int) ([]model.IngestFailure, error)
+	GetFailure(ctx context.Context, id int64) (*model.IngestFailure, error)
+	UpdateFailurePayload(ctx context.Context, id int64, payload string) (*model.IngestFailure, error)
+	UpdateFailureError(ctx context.Context, id int64, failureErr string) error
+	DeleteFailure(ctx context.Context, id int64) error
 }
 
 type Repository struct {
 	OrderRepositoryInterface
+	IngestFailureRepositoryInterface
 }
 
-func NewRepository(db *sqlx.DB) *Repository {
-	return &Repository{
-		OrderRepositoryInterface: NewOrderRepository(db),
+func NewRepository(db *sqlx.DB) (*Repository, error) {
+	orderRepository, err := NewOrderRepository(db)
+	if err != nil {
+		return nil, err
 	}
+	return &Repository{
+		OrderRepositoryInterface:         orderRepository,
+		IngestFailureRepositoryInterface: NewIngestFailureRepository(db),
+	}, nil
 }
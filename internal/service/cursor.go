@@ -0,0 +1,35 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+)
+
+var errInvalidCursor = apperror.Wrap(apperror.ErrInvalidInput, errors.New("invalid cursor"))
+
+// encodeCursor returns an opaque token clients pass back to get the next page.
+func encodeCursor(order *model.Order) (string, error) {
+	data, err := json.Marshal(model.OrderCursor{DateCreated: order.DateCreated, OrderUID: order.OrderUID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(token string) (*model.OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var cursor model.OrderCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.OrderUID == "" || cursor.DateCreated.IsZero() {
+		return nil, errInvalidCursor
+	}
+	return &cursor, nil
+}
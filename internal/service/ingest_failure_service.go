@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/karambo3a/wbtech_test_task/internal/logger"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+	"github.com/karambo3a/wbtech_test_task/internal/repository"
+)
+
+type IngestFailureService struct {
+	repository *repository.Repository
+	orders     OrderServiceInterface
+	dbTimeout  time.Duration
+}
+
+func NewIngestFailureService(repository *repository.Repository, orders OrderServiceInterface, dbTimeout time.Duration) *IngestFailureService {
+	return &IngestFailureService{
+		repository: repository,
+		orders:     orders,
+		dbTimeout:  dbTimeout,
+	}
+}
+
+func (s *IngestFailureService) ListIngestFailures(ctx context.Context, limit int, offset int) ([]model.IngestFailure, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	failures, err := s.repository.ListFailures(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingest failures: %w", err)
+	}
+	return failures, nil
+}
+
+func (s *IngestFailureService) GetIngestFailure(ctx context.Context, id int64) (*model.IngestFailure, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	return s.repository.GetFailure(ctx, id)
+}
+
+func (s *IngestFailureService) UpdateIngestFailure(ctx context.Context, id int64, payload string) (*model.IngestFailure, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	failure, err := s.repository.UpdateFailurePayload(ctx, id, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "ingest failure payload updated", "ingest_failure_id", id)
+	return failure, nil
+}
+
+// ReprocessIngestFailure runs the stored payload through the same path as Kafka messages.
+// On success the entry is removed, otherwise the new error is recorded.
+func (s *IngestFailureService) ReprocessIngestFailure(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	failure, err := s.repository.GetFailure(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.orders.SaveOrder(ctx, []byte(failure.Payload)); err != nil {
+		// the error is recorded even when reprocessing ran out of time
+		recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.dbTimeout)
+		defer cancel()
+		if updateErr := s.repository.UpdateFailureError(recordCtx, id, err.Error()); updateErr != nil {
+			slog.ErrorContext(ctx, "failed to record reprocessing error", "ingest_failure_id", id, logger.Err(updateErr))
+		}
+		return fmt.Errorf("failed to reprocess ingest failure %d: %w", id, err)
+	}
+
+	if err := s.repository.DeleteFailure(ctx, id); err != nil {
+		return fmt.Errorf("ingest failure %d is reprocessed but not removed: %w", id, err)
+	}
+
+	slog.InfoContext(ctx, "ingest failure reprocessed", "ingest_failure_id", id)
+	return nil
+}
+
+func (s *IngestFailureService) DiscardIngestFailure(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	if err := s.repository.DeleteFailure(ctx, id); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "ingest failure discarded", "ingest_failure_id", id)
+	return nil
+}
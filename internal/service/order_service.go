@@ -5,88 +5,524 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+	"github.com/karambo3a/wbtech_test_task/internal/bloom"
 	"github.com/karambo3a/wbtech_test_task/internal/cache"
+	"github.com/karambo3a/wbtech_test_task/internal/config"
 	"github.com/karambo3a/wbtech_test_task/internal/consumer"
+	"github.com/karambo3a/wbtech_test_task/internal/logger"
+	"github.com/karambo3a/wbtech_test_task/internal/metrics"
 	"github.com/karambo3a/wbtech_test_task/internal/model"
 	"github.com/karambo3a/wbtech_test_task/internal/repository"
-	"github.com/redis/go-redis/v9"
+	"github.com/karambo3a/wbtech_test_task/internal/validation"
+	"golang.org/x/sync/singleflight"
 )
 
 type OrderService struct {
 	repository *repository.Repository
 	consumer   consumer.Consumer
-	cache      cache.RedisCache
+	cache      cache.OrderCache
+	cacheWG    sync.WaitGroup
+	warmup     warmupTracker
+	// loads merges concurrent database reads of the same order
+	loads singleflight.Group
+	// knownOrders holds the UIDs of stored orders, nil when the Bloom filter is disabled or not loaded yet.
+	// Only orders saved by this instance are added between rebuilds, so a miss is just a hint
+	knownOrders atomic.Pointer[bloom.Filter]
+	// rebuilding is the filter being loaded, saved orders are added to it too, so it does not miss them
+	rebuilding atomic.Pointer[bloom.Filter]
+
+	// dbTimeout and cacheTimeout bound a single storage operation, so slow queries do not pile up
+	dbTimeout    time.Duration
+	cacheTimeout time.Duration
+	cacheTTL     time.Duration
+
+	earlyRefreshWindow time.Duration
+	negativeTTL        time.Duration
+
+	// accesses counts order reads for the frequent warm-up strategy, nil for the recent one
+	accesses  *accessCounter
+	flushDone chan struct{}
+
+	// stopBackground stops the periodic Bloom filter refresh, the cache warm-up and the flushing of order reads
+	stopBackground context.CancelFunc
 }
 
-func NewOrderService(repository *repository.Repository, consumer consumer.Consumer, cache cache.RedisCache, initLimit int64) *OrderService {
+func NewOrderService(ctx context.Context, repository *repository.Repository, consumer consumer.Consumer, cache cache.OrderCache, cfg *config.Config) *OrderService {
 	service := &OrderService{
 		repository: repository,
 		consumer:   consumer,
 		cache:      cache,
+
+		dbTimeout:    cfg.Database.QueryTimeout,
+		cacheTimeout: cfg.Cache.Timeout,
+		cacheTTL:     cfg.Cache.TTL,
+
+		earlyRefreshWindow: cfg.Cache.EarlyRefreshWindow,
+		negativeTTL:        cfg.Cache.NegativeTTL,
 	}
 
-	orders, err := repository.GetAllOrders(initLimit)
-	if err != nil {
-		log.Fatalf("failed to get orders from database to cache: %v", err)
+	background, stop := context.WithCancel(context.WithoutCancel(ctx))
+	warmupCtx, stopWarmup := context.WithCancel(ctx)
+	service.stopBackground = func() {
+		stopWarmup()
+		stop()
+	}
+
+	// until the filter is loaded all orders are looked up in storage
+	if cfg.Cache.BloomEnabled {
+		service.cacheWG.Add(1)
+		go func() {
+			defer service.cacheWG.Done()
+			service.refreshKnownOrders(background, cfg.Cache)
+		}()
 	}
 
-	if err := service.cache.Init(orders); err != nil {
-		log.Fatalln("failed to get cache from db")
+	// orders are served from the database until they are cached, so warm-up does not delay startup
+	service.warmup.start(cfg.Cache.WarmupStrategy, cfg.Cache.WarmupLimit)
+	service.cacheWG.Add(1)
+	go func() {
+		defer service.cacheWG.Done()
+		service.warmUp(warmupCtx, cfg.Cache)
+	}()
+
+	if cfg.Cache.WarmupStrategy == WarmupFrequent {
+		service.accesses = newAccessCounter()
+		service.flushDone = make(chan struct{})
+		go service.flushAccesses(background, cfg.Cache.AccessFlushInterval, service.flushDone)
 	}
 
-	service.consumer.StartConsuming(service.SaveOrder)
+	service.consumer.StartBatchConsuming(service.SaveOrders)
 	return service
 }
 
-func (s *OrderService) SaveOrder(msg []byte) error {
-	var order model.Order
-	err := json.Unmarshal(msg, &order)
+// refreshKnownOrders loads the Bloom filter and rebuilds it every cfg.BloomRefreshInterval until ctx is done,
+// so orders saved by other instances become known. A failed load keeps the previous filter.
+func (s *OrderService) refreshKnownOrders(ctx context.Context, cfg config.CacheConfig) {
+	ticker := time.NewTicker(cfg.BloomRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.loadKnownOrders(ctx, cfg); err != nil {
+			slog.WarnContext(ctx, "failed to load bloom filter", logger.Err(err))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// loadKnownOrders builds the Bloom filter of the UIDs of all stored orders and replaces the current one.
+func (s *OrderService) loadKnownOrders(ctx context.Context, cfg config.CacheConfig) error {
+	filter := bloom.NewFilter(uint64(cfg.BloomCapacity), cfg.BloomFPRate)
+	s.rebuilding.Store(filter)
+	defer s.rebuilding.Store(nil)
+
+	// the scan reads the whole table, so it has its own timeout rather than the one of a single query
+	dbCtx, cancel := context.WithTimeout(ctx, cfg.BloomLoadTimeout)
+	defer cancel()
+	var count int64
+	err := s.repository.ForEachOrderUID(dbCtx, func(orderUID string) {
+		filter.Add(orderUID)
+		count++
+	})
 	if err != nil {
-		return fmt.Errorf("failed to parse order json: %w", err)
+		return fmt.Errorf("failed to get order uids: %w", err)
 	}
+	s.knownOrders.Store(filter)
 
-	err = s.repository.SaveOrder(&order)
+	if count > cfg.BloomCapacity {
+		slog.WarnContext(ctx, "more orders than bloom filter capacity, false positive rate is higher than configured",
+			"orders", count, "capacity", cfg.BloomCapacity)
+	}
+	slog.InfoContext(ctx, "bloom filter loaded", "orders", count, "size_bytes", filter.SizeBytes())
+	return nil
+}
+
+// addKnownOrder adds the order to the Bloom filter. The filter being rebuilt is read first:
+// if it is already gone, the rebuilt filter is the current one, so the order is never missed.
+func (s *OrderService) addKnownOrder(orderUID string) {
+	if rebuilding := s.rebuilding.Load(); rebuilding != nil {
+		rebuilding.Add(orderUID)
+	}
+	if known := s.knownOrders.Load(); known != nil {
+		known.Add(orderUID)
+	}
+}
+
+// recordBloomMiss counts a lookup missed by the Bloom filter by whether the order exists,
+// an existing order was saved by another instance and is added to the filter.
+func (s *OrderService) recordBloomMiss(orderUID string, found bool) {
+	if !found {
+		metrics.BloomMisses.WithLabelValues("not_found").Inc()
+		return
+	}
+	metrics.BloomMisses.WithLabelValues("found").Inc()
+	s.addKnownOrder(orderUID)
+}
+
+func (s *OrderService) SaveOrder(ctx context.Context, msg []byte) error {
+	order, err := parseOrder(msg)
 	if err != nil {
-		return fmt.Errorf("failed to save new order: %w", err)
+		return err
+	}
+	return s.saveOrder(ctx, order)
+}
+
+// SaveOrders saves all parsed orders in one batch and returns an error per message.
+// Messages that fail parsing, validation or database constraints are quarantined in ingest_failures.
+// If the batch is rejected for a non-transient reason, orders are saved one by one,
+// so a single bad order does not fail the rest.
+func (s *OrderService) SaveOrders(ctx context.Context, msgs []consumer.Message) []error {
+	errs := make([]error, len(msgs))
+	orders := make([]*model.Order, 0, len(msgs))
+	positions := make([]int, 0, len(msgs))
+	for i, msg := range msgs {
+		order, err := parseOrder(msg.Value)
+		if err != nil {
+			metrics.MessagesFailed.WithLabelValues(metrics.ReasonInvalid).Inc()
+			errs[i] = s.quarantine(logger.WithCorrelationID(ctx, msg.CorrelationID), msg, err)
+			continue
+		}
+		orders = append(orders, order)
+		positions = append(positions, i)
+	}
+
+	if len(orders) == 0 {
+		return errs
+	}
+
+	start := time.Now()
+	dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	results, err := s.repository.SaveOrders(dbCtx, orders)
+	cancel()
+	metrics.SaveDuration.WithLabelValues("batch").Observe(metrics.Since(start))
+	switch {
+	case err == nil:
+		counts := make(map[repository.SaveResult]int)
+		for i, order := range orders {
+			counts[results[i]]++
+			msgCtx := logger.WithCorrelationID(ctx, msgs[positions[i]].CorrelationID)
+			errs[positions[i]] = s.handleSaveResult(msgCtx, order, results[i])
+			slog.InfoContext(msgCtx, "order processed", "order_uid", order.OrderUID, "result", results[i].String())
+		}
+		slog.InfoContext(ctx, "batch of orders processed", "size", len(orders),
+			"inserted", counts[repository.SaveResultInserted], "duplicate", counts[repository.SaveResultDuplicate], "conflict", counts[repository.SaveResultConflict])
+	case errors.Is(err, apperror.ErrUnavailable):
+		metrics.MessagesFailed.WithLabelValues(metrics.ReasonUnavailable).Add(float64(len(positions)))
+		for _, pos := range positions {
+			errs[pos] = consumer.NewRetryableError(fmt.Errorf("failed to save new orders: %w", err))
+		}
+	default:
+		slog.WarnContext(ctx, "failed to save batch of orders, saving one by one", "size", len(orders), logger.Err(err))
+		for i, order := range orders {
+			msg := msgs[positions[i]]
+			msgCtx := logger.WithCorrelationID(ctx, msg.CorrelationID)
+			err := s.saveOrder(msgCtx, order)
+			// an order rejected by a database constraint is quarantined like an invalid one
+			if errors.Is(err, apperror.ErrInvalidInput) {
+				err = s.quarantine(msgCtx, msg, err)
+			}
+			errs[positions[i]] = err
+		}
+	}
+	return errs
+}
+
+// quarantine stores a message that cannot become an order, so it can be fixed and reprocessed later.
+func (s *OrderService) quarantine(ctx context.Context, msg consumer.Message, parseErr error) error {
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+
+	id, err := s.repository.SaveFailure(ctx, &model.IngestFailure{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Key:       string(msg.Key),
+		Payload:   string(msg.Value),
+		Error:     parseErr.Error(),
+	})
+	if err != nil {
+		if errors.Is(err, apperror.ErrUnavailable) {
+			return consumer.NewRetryableError(fmt.Errorf("failed to quarantine message: %w", err))
+		}
+		return fmt.Errorf("%w (failed to quarantine message: %v)", parseErr, err)
+	}
+
+	slog.WarnContext(ctx, "message quarantined", "partition", msg.Partition, "offset", msg.Offset, "ingest_failure_id", id, logger.Err(parseErr))
+	return nil
+}
+
+func parseOrder(msg []byte) (*model.Order, error) {
+	var order model.Order
+	if err := json.Unmarshal(msg, &order); err != nil {
+		return nil, apperror.Wrap(apperror.ErrInvalidInput, fmt.Errorf("failed to parse order json: %w", err))
+	}
+	if err := validation.ValidateOrder(&order, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to validate order_uid=%s: %w", order.OrderUID, err)
 	}
+	return &order, nil
+}
 
-	go s.cacheOrderAsync(order.OrderUID, &order)
+func (s *OrderService) saveOrder(ctx context.Context, order *model.Order) error {
+	start := time.Now()
+	dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	result, err := s.repository.SaveOrder(dbCtx, order)
+	cancel()
+	metrics.SaveDuration.WithLabelValues("single").Observe(metrics.Since(start))
+	if err != nil {
+		if errors.Is(err, apperror.ErrUnavailable) {
+			metrics.MessagesFailed.WithLabelValues(metrics.ReasonUnavailable).Inc()
+			return consumer.NewRetryableError(fmt.Errorf("failed to save new order: %w", err))
+		}
+		reason := metrics.ReasonError
+		if errors.Is(err, apperror.ErrInvalidInput) {
+			reason = metrics.ReasonInvalid
+		}
+		metrics.MessagesFailed.WithLabelValues(reason).Inc()
+		return fmt.Errorf("failed to save new order: %w", err)
+	}
 
-	log.Println("order saved")
+	if err := s.handleSaveResult(ctx, order, result); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "order saved", "order_uid", order.OrderUID, "result", result.String())
 	return nil
 }
 
-func (s *OrderService) GetOrder(orderUID string) (*model.Order, error) {
-	order, err := s.cache.Get(context.TODO(), orderUID)
+// handleSaveResult caches inserted orders, skips redelivered duplicates
+// and rejects orders that conflict with the stored ones.
+func (s *OrderService) handleSaveResult(ctx context.Context, order *model.Order, result repository.SaveResult) error {
+	// after any of the results an order with this UID is stored
+	s.addKnownOrder(order.OrderUID)
+
+	switch result {
+	case repository.SaveResultInserted:
+		metrics.MessagesSaved.WithLabelValues(result.String()).Inc()
+		s.cacheOrderAsync(ctx, order.OrderUID, order)
+		return nil
+	case repository.SaveResultDuplicate:
+		metrics.MessagesSaved.WithLabelValues(result.String()).Inc()
+		return nil
+	case repository.SaveResultConflict:
+		metrics.MessagesFailed.WithLabelValues(metrics.ReasonConflict).Inc()
+		return fmt.Errorf("failed to save order_uid=%s: %w", order.OrderUID, repository.ErrOrderConflict)
+	default:
+		metrics.MessagesFailed.WithLabelValues(metrics.ReasonError).Inc()
+		return fmt.Errorf("failed to save order_uid=%s: unexpected result %s", order.OrderUID, result)
+	}
+}
+
+// GetOrder returns the order from the cache or, on a miss, from the database.
+// Returned orders may be shared between concurrent callers and must not be modified.
+func (s *OrderService) GetOrder(ctx context.Context, orderUID string) (*model.Order, error) {
+	// the filter does not know the orders saved by other instances since its last rebuild,
+	// so a miss is not an answer: the order is still looked up
+	known := s.knownOrders.Load()
+	bloomMiss := known != nil && !known.Test(orderUID)
+
+	start := time.Now()
+	cacheCtx, cancel := context.WithTimeout(ctx, s.cacheTimeout)
+	order, expiresAt, err := s.cache.Get(cacheCtx, orderUID)
+	cancel()
 	if err == nil {
+		if bloomMiss {
+			s.recordBloomMiss(orderUID, true)
+		}
+		metrics.GetDuration.WithLabelValues("cache").Observe(metrics.Since(start))
+		s.countAccess(orderUID)
+		if s.refreshDue(expiresAt, time.Now()) {
+			s.refreshAsync(ctx, orderUID)
+		}
 		return order, nil
 	}
 
-	if !errors.Is(err, redis.Nil) {
+	if errors.Is(err, cache.ErrCachedNotFound) {
+		if bloomMiss {
+			s.recordBloomMiss(orderUID, false)
+		}
+		metrics.GetNotFound.WithLabelValues("cache").Inc()
 		return &model.Order{}, fmt.Errorf("failed to get order_uid=%s: %w", orderUID, err)
 	}
 
-	order, err = s.repository.GetOrder(orderUID)
-	if err != nil {
-		return &model.Order{}, fmt.Errorf("order with order_uid=%s is not found: %w", orderUID, err)
+	// an unavailable cache must not fail reads, the database is the source of truth
+	if !errors.Is(err, apperror.ErrNotFound) {
+		slog.WarnContext(ctx, "cache error, reading order from db", "order_uid", orderUID, logger.Err(err))
 	}
 
-	orderAsync := *order
-	go s.cacheOrderAsync(orderUID, &orderAsync)
+	order, err = s.loadOrder(ctx, orderUID)
+	if bloomMiss && (err == nil || errors.Is(err, apperror.ErrNotFound)) {
+		s.recordBloomMiss(orderUID, err == nil)
+	}
+	if err != nil {
+		return &model.Order{}, fmt.Errorf("failed to get order_uid=%s: %w", orderUID, err)
+	}
 
-	log.Println("got order by id")
+	metrics.GetDuration.WithLabelValues("db").Observe(metrics.Since(start))
+	s.countAccess(orderUID)
+	slog.DebugContext(ctx, "got order from db", "order_uid", orderUID)
 	return order, nil
 }
 
-func (s *OrderService) cacheOrderAsync(orderUID string, order *model.Order) {
-	if err := s.cache.Set(context.TODO(), orderUID, order, 24*time.Hour); err != nil {
-		log.Printf("failed to save in cache order_uid=%s: %v", orderUID, err)
+// loadOrder reads the order from the database and caches it. Concurrent calls for the same
+// order wait for a single read, which is therefore not cancelled with ctx of the caller that
+// started it; a caller whose ctx is done stops waiting.
+func (s *OrderService) loadOrder(ctx context.Context, orderUID string) (*model.Order, error) {
+	loaded := s.loads.DoChan(orderUID, func() (any, error) {
+		dbCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.dbTimeout)
+		defer cancel()
+		order, err := s.repository.GetOrder(dbCtx, orderUID)
+		if err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				metrics.GetNotFound.WithLabelValues("db").Inc()
+				s.cacheNotFoundAsync(ctx, orderUID)
+			}
+			return nil, err
+		}
+
+		orderAsync := *order
+		s.cacheOrderAsync(ctx, orderUID, &orderAsync)
+		return order, nil
+	})
+
+	select {
+	case res := <-loaded:
+		if res.Shared {
+			metrics.GetSharedLoads.Inc()
+		}
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*model.Order), nil
+	case <-ctx.Done():
+		return nil, apperror.Wrap(apperror.ErrUnavailable, ctx.Err())
 	}
 }
 
-func (s *OrderService) CloseConsumer() {
-	s.consumer.Close()
+// refreshDue reports whether an order cached until expiresAt should be reloaded early.
+// Within the refresh window the chance grows linearly towards expiration, so a hot order
+// is reloaded by one of the first requests in the window instead of expiring for all of them at once.
+func (s *OrderService) refreshDue(expiresAt, now time.Time) bool {
+	if s.earlyRefreshWindow <= 0 || expiresAt.IsZero() {
+		return false
+	}
+	left := expiresAt.Sub(now)
+	if left >= s.earlyRefreshWindow {
+		return false
+	}
+	return rand.Float64() >= float64(left)/float64(s.earlyRefreshWindow)
+}
+
+// refreshAsync reloads the order into the cache in the background, the request is served from the cache meanwhile.
+func (s *OrderService) refreshAsync(ctx context.Context, orderUID string) {
+	metrics.CacheEarlyRefreshes.Inc()
+	ctx = context.WithoutCancel(ctx)
+	s.cacheWG.Add(1)
+	go func() {
+		defer s.cacheWG.Done()
+		if _, err := s.loadOrder(ctx, orderUID); err != nil {
+			slog.WarnContext(ctx, "failed to refresh order in cache", "order_uid", orderUID, logger.Err(err))
+		}
+	}()
+}
+
+// ListOrders returns a page of orders matching filter that go after the cursor token,
+// an empty cursor starts from the newest order.
+func (s *OrderService) ListOrders(ctx context.Context, filter model.OrderFilter, cursor string, limit int) (*model.OrderPage, error) {
+	var after *model.OrderCursor
+	if cursor != "" {
+		var err error
+		if after, err = decodeCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	// one extra order tells whether there is a next page
+	ctx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+	orders, err := s.repository.ListOrders(ctx, filter, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	page := &model.OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		if page.NextCursor, err = encodeCursor(page.Orders[limit-1]); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// cacheOrderAsync writes the order to the cache in the background. The write is not
+// cancelled with ctx, as the request that triggered it usually ends first.
+func (s *OrderService) cacheOrderAsync(ctx context.Context, orderUID string, order *model.Order) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.cacheTimeout)
+	s.cacheWG.Add(1)
+	go func() {
+		defer s.cacheWG.Done()
+		defer cancel()
+		if err := s.cache.Set(ctx, orderUID, order, s.cacheTTL); err != nil {
+			slog.WarnContext(ctx, "failed to save order in cache", "order_uid", orderUID, logger.Err(err))
+		}
+	}()
+}
+
+// cacheNotFoundAsync caches in the background that the order does not exist, so repeated
+// requests for a mistyped or guessed UID do not reach the database.
+func (s *OrderService) cacheNotFoundAsync(ctx context.Context, orderUID string) {
+	if s.negativeTTL <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.cacheTimeout)
+	s.cacheWG.Add(1)
+	go func() {
+		defer s.cacheWG.Done()
+		defer cancel()
+		if err := s.cache.SetNotFound(ctx, orderUID, s.negativeTTL); err != nil {
+			slog.WarnContext(ctx, "failed to save not found order in cache", "order_uid", orderUID, logger.Err(err))
+		}
+	}()
+}
+
+// Shutdown stops consuming after in-flight messages are saved and committed,
+// saves the counted order reads and then waits for pending cache writes until ctx is done.
+// Every step runs even if an earlier one fails, and all their errors are returned.
+func (s *OrderService) Shutdown(ctx context.Context) error {
+	var errs []error
+	if err := s.consumer.Close(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close consumer: %w", err))
+	}
+
+	s.stopBackground()
+	if s.accesses != nil {
+		select {
+		case <-s.flushDone:
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("failed to save order reads: %w", ctx.Err()))
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.cacheWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		slog.Info("pending cache writes finished")
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("failed to wait for pending cache writes: %w", ctx.Err()))
+	}
+	return errors.Join(errs...)
 }
@@ -1,7 +1,10 @@
 package service
 
 import (
+	"context"
+
 	"github.com/karambo3a/wbtech_test_task/internal/cache"
+	"github.com/karambo3a/wbtech_test_task/internal/config"
 	"github.com/karambo3a/wbtech_test_task/internal/consumer"
 	"github.com/karambo3a/wbtech_test_task/internal/model"
 	"github.com/karambo3a/wbtech_test_task/internal/repository"
@@ -10,17 +13,31 @@ import (
 //go:generate mockgen -source=service.go -destination=../../test/mocks/service_mock.go
 
 type OrderServiceInterface interface {
-	SaveOrder(msg []byte) error
-	GetOrder(orderUID string) (*model.Order, error)
-	CloseConsumer()
+	SaveOrder(ctx context.Context, msg []byte) error
+	SaveOrders(ctx context.Context, msgs []consumer.Message) []error
+	GetOrder(ctx context.Context, orderUID string) (*model.Order, error)
+	ListOrders(ctx context.Context, filter model.OrderFilter, cursor string, limit int) (*model.OrderPage, error)
+	WarmupProgress() WarmupProgress
+	Shutdown(ctx context.Context) error
+}
+
+type IngestFailureServiceInterface interface {
+	ListIngestFailures(ctx context.Context, limit int, offset int) ([]model.IngestFailure, error)
+	GetIngestFailure(ctx context.Context, id int64) (*model.IngestFailure, error)
+	UpdateIngestFailure(ctx context.Context, id int64, payload string) (*model.IngestFailure, error)
+	ReprocessIngestFailure(ctx context.Context, id int64) error
+	DiscardIngestFailure(ctx context.Context, id int64) error
 }
 
 type Service struct {
 	OrderServiceInterface
+	IngestFailureServiceInterface
 }
 
-func NewService(repository *repository.Repository, consumer consumer.Consumer, cache cache.RedisCache, initLimit int64) *Service {
+func NewService(ctx context.Context, repository *repository.Repository, consumer consumer.Consumer, cache cache.OrderCache, cfg *config.Config) *Service {
+	orderService := NewOrderService(ctx, repository, consumer, cache, cfg)
 	return &Service{
-		OrderServiceInterface: NewOrderService(repository, consumer, cache, initLimit),
+		OrderServiceInterface:         orderService,
+		IngestFailureServiceInterface: NewIngestFailureService(repository, orderService, cfg.Database.QueryTimeout),
 	}
 }
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/karambo3a/wbtech_test_task/internal/config"
+	"github.com/karambo3a/wbtech_test_task/internal/logger"
+	"github.com/karambo3a/wbtech_test_task/internal/metrics"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+)
+
+// cache warm-up states
+const (
+	WarmupRunning = "running"
+	WarmupDone    = "done"
+	WarmupFailed  = "failed"
+)
+
+// cache warm-up strategies
+const (
+	WarmupRecent   = "recent"
+	WarmupFrequent = "frequent"
+)
+
+// maxTrackedOrders bounds the orders whose reads are counted between two flushes,
+// reads of further orders are not counted until the next flush.
+const maxTrackedOrders = 100000
+
+type WarmupProgress struct {
+	State    string
+	Strategy string
+	Limit    int64
+	// Cached and Failed count the orders written to the cache and the ones whose write failed
+	Cached int64
+	Failed int64
+	Error  string
+}
+
+type warmupTracker struct {
+	mu       sync.Mutex
+	progress WarmupProgress
+}
+
+func (t *warmupTracker) get() WarmupProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.progress
+}
+
+func (t *warmupTracker) start(strategy string, limit int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress = WarmupProgress{State: WarmupRunning, Strategy: strategy, Limit: limit}
+}
+
+func (t *warmupTracker) addPage(size int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		t.progress.Failed += int64(size)
+	} else {
+		t.progress.Cached += int64(size)
+	}
+}
+
+func (t *warmupTracker) finish(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.progress.State = WarmupDone
+	if err != nil {
+		t.progress.State = WarmupFailed
+		t.progress.Error = err.Error()
+	}
+}
+
+// warmUp fills the cache with up to cfg.WarmupLimit orders chosen by the strategy. Pages are read
+// one after another and written by up to cfg.WarmupConcurrency workers, so reading overlaps with writing.
+// The frequent strategy tops up with recent orders if fewer orders have been read.
+func (s *OrderService) warmUp(ctx context.Context, cfg config.CacheConfig) {
+	start := time.Now()
+	pages := make(chan []*model.Order)
+	var workers sync.WaitGroup
+	for range cfg.WarmupConcurrency {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for page := range pages {
+				cacheCtx, cancel := context.WithTimeout(ctx, s.cacheTimeout)
+				err := s.cache.SetMany(cacheCtx, page, s.cacheTTL)
+				cancel()
+				s.warmup.addPage(len(page), err)
+				if err != nil {
+					slog.WarnContext(ctx, "failed to write warm-up page to cache", "orders", len(page), logger.Err(err))
+				}
+			}
+		}()
+	}
+
+	var read int64
+	var err error
+	if cfg.WarmupStrategy == WarmupFrequent {
+		read, err = s.readWarmupPages(ctx, s.repository.GetMostAccessedOrders, cfg.WarmupLimit, cfg.WarmupPageSize, pages)
+	}
+	if err == nil && read < cfg.WarmupLimit {
+		_, err = s.readWarmupPages(ctx, s.repository.GetRecentOrders, cfg.WarmupLimit-read, cfg.WarmupPageSize, pages)
+	}
+	close(pages)
+	workers.Wait()
+
+	metrics.CacheWarmupDuration.Set(metrics.Since(start))
+	s.warmup.finish(err)
+	progress := s.warmup.get()
+	if err != nil {
+		slog.ErrorContext(ctx, "cache warm-up failed", "cached", progress.Cached, "failed", progress.Failed, logger.Err(err))
+		return
+	}
+	slog.InfoContext(ctx, "cache warmed up", "strategy", progress.Strategy, "cached", progress.Cached, "failed", progress.Failed,
+		"duration_ms", time.Since(start).Milliseconds())
+}
+
+// readWarmupPages sends pages of up to limit orders to pages and returns how many orders were read.
+func (s *OrderService) readWarmupPages(ctx context.Context, get func(ctx context.Context, offset int64, limit int64) ([]*model.Order, error),
+	limit int64, pageSize int, pages chan<- []*model.Order) (int64, error) {
+	var offset int64
+	for offset < limit {
+		size := min(int64(pageSize), limit-offset)
+		dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+		orders, err := get(dbCtx, offset, size)
+		cancel()
+		if err != nil {
+			return offset, fmt.Errorf("failed to get orders to cache: %w", err)
+		}
+		if len(orders) == 0 {
+			return offset, nil
+		}
+
+		select {
+		case pages <- orders:
+		case <-ctx.Done():
+			return offset, fmt.Errorf("cache warm-up interrupted: %w", ctx.Err())
+		}
+		offset += int64(len(orders))
+		if int64(len(orders)) < size {
+			return offset, nil
+		}
+	}
+	return offset, nil
+}
+
+// WarmupProgress reports the state of the cache warm-up.
+func (s *OrderService) WarmupProgress() WarmupProgress {
+	return s.warmup.get()
+}
+
+// accessCounter counts order reads between flushes to the database.
+type accessCounter struct {
+	mu   sync.Mutex
+	hits map[string]int64
+}
+
+func newAccessCounter() *accessCounter {
+	return &accessCounter{hits: make(map[string]int64)}
+}
+
+func (c *accessCounter) add(orderUID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.hits[orderUID]; ok || len(c.hits) < maxTrackedOrders {
+		c.hits[orderUID]++
+	}
+}
+
+func (c *accessCounter) take() map[string]int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hits := c.hits
+	c.hits = make(map[string]int64)
+	return hits
+}
+
+// flushAccesses periodically saves the counted reads for the frequent warm-up strategy,
+// the last ones are saved when ctx is done.
+func (s *OrderService) flushAccesses(ctx context.Context, interval time.Duration, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flushAccessesOnce(ctx)
+		case <-ctx.Done():
+			s.flushAccessesOnce(context.WithoutCancel(ctx))
+			return
+		}
+	}
+}
+
+func (s *OrderService) flushAccessesOnce(ctx context.Context) {
+	hits := s.accesses.take()
+	if len(hits) == 0 {
+		return
+	}
+	dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancel()
+	if err := s.repository.AddOrderAccesses(dbCtx, hits); err != nil {
+		slog.WarnContext(ctx, "failed to save order reads", "orders", len(hits), logger.Err(err))
+	}
+}
+
+func (s *OrderService) countAccess(orderUID string) {
+	if s.accesses != nil {
+		s.accesses.add(orderUID)
+	}
+}
@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+)
+
+// maxClockSkew tolerates producers whose clocks run slightly ahead.
+const maxClockSkew = 5 * time.Minute
+
+var phoneRegexp = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Field+": "+v.Message)
+	}
+	return "invalid order: " + strings.Join(messages, "; ")
+}
+
+func (e *Error) Unwrap() error {
+	return apperror.ErrInvalidInput
+}
+
+type validator struct {
+	violations []Violation
+}
+
+func (v *validator) check(ok bool, field string, format string, args ...any) {
+	if !ok {
+		v.violations = append(v.violations, Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+func (v *validator) required(value string, field string) {
+	v.check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+// ValidateOrder checks the order before it is persisted and returns *Error
+// with every field-level violation found, or nil if the order is valid.
+func ValidateOrder(order *model.Order, now time.Time) error {
+	v := &validator{}
+
+	v.required(order.OrderUID, "order_uid")
+	v.required(order.TrackNumber, "track_number")
+	v.required(order.Entry, "entry")
+	v.required(order.Locale, "locale")
+	v.required(order.CustomerID, "customer_id")
+	v.required(order.DeliveryService, "delivery_service")
+	v.check(!order.DateCreated.IsZero(), "date_created", "is required")
+	v.check(!order.DateCreated.After(now.Add(maxClockSkew)), "date_created", "is in the future")
+
+	validateDelivery(v, &order.Delivery)
+	validatePayment(v, &order.Payment)
+
+	v.check(len(order.Items) > 0, "items", "at least one item is required")
+	goodsTotal := 0
+	for i := range order.Items {
+		validateItem(v, &order.Items[i], fmt.Sprintf("items[%d]", i), order.TrackNumber)
+		goodsTotal += order.Items[i].TotalPrice
+	}
+
+	payment := order.Payment
+	v.check(payment.GoodsTotal == goodsTotal, "payment.goods_total",
+		"must be equal to the sum of items total_price %d, got %d", goodsTotal, payment.GoodsTotal)
+	expectedAmount := payment.GoodsTotal + payment.DeliveryCost + payment.CustomFee
+	v.check(payment.Amount == expectedAmount, "payment.amount",
+		"must be equal to goods_total + delivery_cost + custom_fee = %d, got %d", expectedAmount, payment.Amount)
+
+	if len(v.violations) > 0 {
+		return &Error{Violations: v.violations}
+	}
+	return nil
+}
+
+func validateDelivery(v *validator, delivery *model.Delivery) {
+	v.required(delivery.Name, "delivery.name")
+	v.required(delivery.Zip, "delivery.zip")
+	v.required(delivery.City, "delivery.city")
+	v.required(delivery.Address, "delivery.address")
+	v.required(delivery.Region, "delivery.region")
+	v.check(phoneRegexp.MatchString(delivery.Phone), "delivery.phone", "must be in E.164 format, got %q", delivery.Phone)
+	address, err := mail.ParseAddress(delivery.Email)
+	v.check(err == nil && address.Address == delivery.Email, "delivery.email", "must be a valid email address, got %q", delivery.Email)
+}
+
+func validatePayment(v *validator, payment *model.Payment) {
+	v.required(payment.Transaction, "payment.transaction")
+	v.required(payment.Provider, "payment.provider")
+	v.required(payment.Bank, "payment.bank")
+	v.check(isCurrencyCode(payment.Currency), "payment.currency", "must be an ISO 4217 currency code, got %q", payment.Currency)
+	v.check(payment.Amount >= 0, "payment.amount", "must not be negative")
+	v.check(payment.DeliveryCost >= 0, "payment.delivery_cost", "must not be negative")
+	v.check(payment.GoodsTotal >= 0, "payment.goods_total", "must not be negative")
+	v.check(payment.CustomFee >= 0, "payment.custom_fee", "must not be negative")
+	v.check(payment.PaymentDt > 0, "payment.payment_dt", "is required")
+}
+
+func validateItem(v *validator, item *model.Item, field string, trackNumber string) {
+	v.required(item.Rid, field+".rid")
+	v.required(item.Name, field+".name")
+	v.required(item.Brand, field+".brand")
+	v.check(item.TrackNumber == trackNumber, field+".track_number",
+		"must match order track_number %q, got %q", trackNumber, item.TrackNumber)
+	v.check(item.Price >= 0, field+".price", "must not be negative")
+	v.check(item.Sale >= 0 && item.Sale <= 100, field+".sale", "must be between 0 and 100, got %d", item.Sale)
+
+	// total_price is price with the sale applied; one unit of rounding either way is accepted
+	expected := item.Price * (100 - item.Sale) / 100
+	v.check(item.TotalPrice >= expected-1 && item.TotalPrice <= expected+1, field+".total_price",
+		"must be price with sale applied = %d, got %d", expected, item.TotalPrice)
+}
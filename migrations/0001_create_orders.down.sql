@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS orders_x_items;
+DROP TABLE IF EXISTS items;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS deliveries;
@@ -0,0 +1,67 @@
+CREATE TABLE IF NOT EXISTS deliveries
+(
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(255) NOT NULL,
+    zip VARCHAR(255) NOT NULL,
+    city VARCHAR(255) NOT NULL,
+    address VARCHAR(255) NOT NULL,
+    region VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL CHECK (position('@' IN email) > 0),
+    UNIQUE (name, phone, zip, city, address, region, email)
+);
+
+CREATE TABLE IF NOT EXISTS payments
+(
+    id SERIAL PRIMARY KEY,
+    transaction VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    currency VARCHAR(255) NOT NULL,
+    provider VARCHAR(255) NOT NULL,
+    amount INTEGER NOT NULL,
+    payment_dt BIGINT NOT NULL,
+    bank VARCHAR(255) NOT NULL,
+    delivery_cost INTEGER NOT NULL,
+    goods_total INTEGER NOT NULL,
+    custom_fee INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS orders
+(
+    order_uid VARCHAR(255) PRIMARY KEY,
+    track_number VARCHAR(255) NOT NULL,
+    entry VARCHAR(255) NOT NULL,
+    delivery_id INTEGER NOT NULL REFERENCES deliveries(id) ON DELETE CASCADE,
+    payment_id INTEGER NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    locale VARCHAR(255) NOT NULL,
+    internal_signature VARCHAR(255) NOT NULL DEFAULT '',
+    customer_id VARCHAR(255) NOT NULL,
+    delivery_service VARCHAR(255) NOT NULL,
+    shardkey VARCHAR(255) NOT NULL,
+    sm_id INTEGER NOT NULL,
+    date_created TIMESTAMPTZ NOT NULL,
+    oof_shard VARCHAR(255) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS items
+(
+    id SERIAL PRIMARY KEY,
+    chrt_id INTEGER NOT NULL,
+    track_number VARCHAR(255) NOT NULL,
+    price INTEGER NOT NULL,
+    rid VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    sale INTEGER NOT NULL CHECK (sale >= 0 AND sale <= 100),
+    size VARCHAR(10) NOT NULL,
+    total_price INTEGER NOT NULL,
+    nm_id INTEGER NOT NULL,
+    brand VARCHAR(255) NOT NULL,
+    status INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS orders_x_items
+(
+    id SERIAL PRIMARY KEY,
+    order_uid VARCHAR(255) NOT NULL REFERENCES orders(order_uid) ON DELETE CASCADE,
+    item_id INTEGER NOT NULL REFERENCES items(id) ON DELETE CASCADE
+);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS ingest_failures
+(
+    id SERIAL PRIMARY KEY,
+    topic VARCHAR(255) NOT NULL,
+    partition INTEGER NOT NULL,
+    kafka_offset BIGINT NOT NULL,
+    message_key BYTEA NOT NULL DEFAULT '',
+    payload BYTEA NOT NULL,
+    error TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    failed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS ingest_failures_failed_at_idx ON ingest_failures (failed_at DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS orders_customer_id_idx;
+DROP INDEX IF EXISTS orders_date_created_idx;
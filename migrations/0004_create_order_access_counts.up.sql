@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS order_access_counts
+(
+    order_uid VARCHAR(255) PRIMARY KEY REFERENCES orders(order_uid) ON DELETE CASCADE,
+    hits BIGINT NOT NULL DEFAULT 0,
+    last_accessed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS order_access_counts_hits_idx ON order_access_counts (hits DESC, order_uid);
@@ -0,0 +1,14 @@
+// Package migrations embeds the database schema migrations and the optional seed data.
+package migrations
+
+import "embed"
+
+// FS holds numbered migrations named <version>_<name>.up.sql and <version>_<name>.down.sql.
+//
+//go:embed *.sql
+var FS embed.FS
+
+// SeedFS holds demo data applied by the seed command, files are applied in name order.
+//
+//go:embed seed/*.sql
+var SeedFS embed.FS
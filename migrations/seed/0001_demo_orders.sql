@@ -0,0 +1,145 @@
+-- Demo orders, skipped for order_uids that are already stored.
+
+WITH d AS (
+    INSERT INTO deliveries (name, phone, zip, city, address, region, email)
+    VALUES ('Test Testov', '+9720000000', '2639809', 'Kiryat Mozkin', 'Ploshad Mira 15', 'Kraiot', 'test@gmail.com')
+    ON CONFLICT (name, phone, zip, city, address, region, email) DO UPDATE SET name = EXCLUDED.name
+    RETURNING id
+), p AS (
+    INSERT INTO payments (transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
+    SELECT 'b563feb7b2b84b6test', '', 'USD', 'wbpay', 1817, 1637907727, 'alpha', 1500, 317, 0
+    WHERE NOT EXISTS (SELECT 1 FROM orders WHERE order_uid = 'b563feb7b2b84b6test')
+    RETURNING id
+), o AS (
+    INSERT INTO orders (order_uid, track_number, entry, delivery_id, payment_id, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
+    SELECT 'b563feb7b2b84b6test', 'WBILMTESTTRACK', 'WBIL', d.id, p.id, 'en', '', 'test', 'meest', '9', 99, '2021-11-26T06:22:19Z'::timestamptz, '1'
+    FROM d, p
+    RETURNING order_uid
+), i AS (
+    INSERT INTO items (chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
+    SELECT 9934930, 'WBILMTESTTRACK', 453, 'ab4219087a764ae0btest', 'Mascaras', 30, '0', 317, 2389212, 'Vivienne Sabo', 202
+    WHERE NOT EXISTS (SELECT 1 FROM orders WHERE order_uid = 'b563feb7b2b84b6test')
+    RETURNING id
+)
+INSERT INTO orders_x_items (order_uid, item_id)
+SELECT o.order_uid, i.id FROM o, i;
+
+WITH d AS (
+    INSERT INTO deliveries (name, phone, zip, city, address, region, email)
+    VALUES ('John Smith', '+442012345678', 'SW1A 1AA', 'London', '10 Downing Street', 'Greater London', 'john.smith@email.com')
+    ON CONFLICT (name, phone, zip, city, address, region, email) DO UPDATE SET name = EXCLUDED.name
+    RETURNING id
+), p AS (
+    INSERT INTO payments (transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
+    SELECT 'order_1700000001_1234', 'req_0001', 'GBP', 'stripe', 5420, 1672534891, 'barclays', 500, 4920, 0
+    WHERE NOT EXISTS (SELECT 1 FROM orders WHERE order_uid = 'order_1700000001_1234')
+    RETURNING id
+), o AS (
+    INSERT INTO orders (order_uid, track_number, entry, delivery_id, payment_id, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
+    SELECT 'order_1700000001_1234', 'WBILTRACK000001', 'WBIL', d.id, p.id, 'en', 'signature_001', 'customer_001', 'dhl', '1', 42, '2023-01-15T14:28:31Z'::timestamptz, '1'
+    FROM d, p
+    RETURNING order_uid
+), i AS (
+    INSERT INTO items (chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
+    SELECT 87654321, 'WBILTRACK000001', 2460, 'rid_0001', 'Sports Sneakers', 15, '42', 2091, 6543210, 'Nike', 200
+    WHERE NOT EXISTS (SELECT 1 FROM orders WHERE order_uid = 'order_1700000001_1234')
+    RETURNING id
+)
+INSERT INTO orders_x_items (order_uid, item_id)
+SELECT o.order_uid, i.id FROM o, i;
+
+WITH d AS (
+    INSERT INTO deliveries (name, phone, zip, city, address, region, email)
+    VALUES ('Emma Johnson', '+13125551234', '10001', 'New York', '350 5th Avenue', 'New York', 'emma.johnson@email.com')
+    ON CONFLICT (name, phone, zip, city, address, region, email) DO UPDATE SET name = EXCLUDED.name
+    RETURNING id
+), p AS (
+    INSERT INTO payments (transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
+    SELECT 'order_1700000002_5678', 'req_0002', 'USD', 'paypal', 8900, 1672621291, 'chase', 300, 8600, 0
+    WHERE NOT EXISTS (SELECT 1 FROM orders WHERE order_uid = 'order_1700000002_5678')
+    RETURNING id
+), o AS (
+    INSERT INTO orders (order_uid, track_number, entry, delivery_id, payment_id, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
+    SELECT 'order_1700000002_5678', 'WBILTRACK000002', 'WBIL', d.id, p.id, 'en', 'signature_002', 'customer_002', 'fedex', '2', 43, '2023-01-16T10:15:22Z'::timestamptz, '2'
+    FROM d, p
+    RETURNING order_uid
+), i AS (
+    INSERT INTO items (chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
+    SELECT 87654322, 'WBILTRACK000002', 4300, 'rid_0002', 'Smartphone', 10, '0', 3870, 6543211, 'Apple', 200
+    WHERE NOT EXISTS (SELECT 1 FROM orders WHERE order_uid = 'order_1700000002_5678')
+    RETURNING id
+)
+INSERT INTO orders_x_items (order_uid, item_id)
+SELECT o.order_uid, i.id FROM o, i;
+
+WITH d AS (
+    INSERT INTO deliveries (name, phone, zip, city, address, region, email)
+    VALUES ('Michael Brown', '+61391234567', '2000', 'Sydney', '1 Macquarie Street', 'NSW', 'michael.brown@email.com')
+    ON CONFLICT (name, phone, zip, city, address, region, email) DO UPDATE SET name = EXCLUDED.name
+    RETURNING id
+), p AS (
+    INSERT INTO payments (transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
+    SELECT 'order_1700000003_9012', 'req_0003', 'AUD', 'afterpay', 12500, 1672707691, 'commonwealth', 700, 11800, 0
+    WHERE NOT EXISTS (SELECT 1 FROM orders WHERE order_uid = 'order_1700000003_9012')
+    RETURNING id
+), o AS (
+    INSERT INTO orders (order_uid, track_number, entry, delivery_id, payment_id, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
+    SELECT 'order_1700000003_9012', 'WBILTRACK000003', 'WBIL', d.id, p.id, 'en', 'signature_003', 'customer_003', 'australia post', '3', 44, '2023-01-17T16:45:18Z'::timestamptz, '3'
+    FROM d, p
+    RETURNING order_uid
+), i AS (
+    INSERT INTO items (chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
+    SELECT 87654323, 'WBILTRACK000003', 5900, 'rid_0003', 'Laptop', 5, '15.6', 5605, 6543212, 'Dell', 200
+    WHERE NOT EXISTS (SELECT 1 FROM orders WHERE order_uid = 'order_1700000003_9012')
+    RETURNING id
+)
+INSERT INTO orders_x_items (order_uid, item_id)
+SELECT o.order_uid, i.id FROM o, i;
+
+WITH d AS (
+    INSERT INTO deliveries (name, phone, zip, city, address, region, email)
+    VALUES ('Sarah Wilson', '+498912345678', '10115', 'Berlin', 'Unter den Linden 77', 'Berlin', 'sarah.wilson@email.com')
+    ON CONFLICT (name, phone, zip, city, address, region, email) DO UPDATE SET name = EXCLUDED.name
+    RETURNING id
+), p AS (
+    INSERT INTO payments (transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
+    SELECT 'order_1700000004_3456', 'req_0004', 'EUR', 'klarna', 7800, 1672794091, 'deutsche', 400, 7400, 0
+    WHERE NOT EXISTS (SELECT 1 FROM orders WHERE order_uid = 'order_1700000004_3456')
+    RETURNING id
+), o AS (
+    INSERT INTO orders (order_uid, track_number, entry, delivery_id, payment_id, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
+    SELECT 'order_1700000004_3456', 'WBILTRACK000004', 'WBIL', d.id, p.id, 'en', 'signature_004', 'customer_004', 'dhl', '4', 45, '2023-01-18T09:30:45Z'::timestamptz, '4'
+    FROM d, p
+    RETURNING order_uid
+), i AS (
+    INSERT INTO items (chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
+    SELECT 87654324, 'WBILTRACK000004', 3700, 'rid_0004', 'Tablet', 20, '10.1', 2960, 6543213, 'Samsung', 200
+    WHERE NOT EXISTS (SELECT 1 FROM orders WHERE order_uid = 'order_1700000004_3456')
+    RETURNING id
+)
+INSERT INTO orders_x_items (order_uid, item_id)
+SELECT o.order_uid, i.id FROM o, i;
+
+WITH d AS (
+    INSERT INTO deliveries (name, phone, zip, city, address, region, email)
+    VALUES ('David Taylor', '+14165551234', 'M5V 2T6', 'Toronto', '1 Dundas Street West', 'Ontario', 'david.taylor@email.com')
+    ON CONFLICT (name, phone, zip, city, address, region, email) DO UPDATE SET name = EXCLUDED.name
+    RETURNING id
+), p AS (
+    INSERT INTO payments (transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee)
+    SELECT 'order_1700000005_7890', 'req_0005', 'CAD', 'shopify', 15600, 1672880491, 'royal bank', 600, 15000, 0
+    WHERE NOT EXISTS (SELECT 1 FROM orders WHERE order_uid = 'order_1700000005_7890')
+    RETURNING id
+), o AS (
+    INSERT INTO orders (order_uid, track_number, entry, delivery_id, payment_id, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard)
+    SELECT 'order_1700000005_7890', 'WBILTRACK000005', 'WBIL', d.id, p.id, 'en', 'signature_005', 'customer_005', 'canada post', '5', 46, '2023-01-19T14:20:33Z'::timestamptz, '5'
+    FROM d, p
+    RETURNING order_uid
+), i AS (
+    INSERT INTO items (chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status)
+    SELECT 87654325, 'WBILTRACK000005', 7500, 'rid_0005', 'Digital Camera', 25, '0', 5625, 6543214, 'Canon', 200
+    WHERE NOT EXISTS (SELECT 1 FROM orders WHERE order_uid = 'order_1700000005_7890')
+    RETURNING id
+)
+INSERT INTO orders_x_items (order_uid, item_id)
+SELECT o.order_uid, i.id FROM o, i;
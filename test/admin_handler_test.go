@@ -0,0 +1,200 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+	"github.com/karambo3a/wbtech_test_task/internal/handlers"
+	"github.com/karambo3a/wbtech_test_task/internal/health"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+	"github.com/karambo3a/wbtech_test_task/internal/repository"
+	"github.com/karambo3a/wbtech_test_task/internal/service"
+	"github.com/karambo3a/wbtech_test_task/internal/validation"
+	mock "github.com/karambo3a/wbtech_test_task/test/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerIngestFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockIngestFailureService := mock.NewMockIngestFailureServiceInterface(ctrl)
+	mockService := &service.Service{IngestFailureServiceInterface: mockIngestFailureService}
+	router := handlers.NewHandler(mockService, health.NewHealth(time.Second), "secret").InitRouts()
+
+	failure := model.IngestFailure{
+		ID:        1,
+		Topic:     "order",
+		Partition: 0,
+		Offset:    42,
+		Key:       "order_uid1",
+		Payload:   `{"order_uid":`,
+		Error:     "failed to parse order json",
+		FailedAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	failureJSON := `{"id":1,"topic":"order","partition":0,"offset":42,"key":"order_uid1","payload":"{\"order_uid\":","error":"failed to parse order json","attempts":0,"failed_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"}`
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		token          string
+		body           string
+		mockBehavior   func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "unauthorized",
+			method:         http.MethodGet,
+			target:         "/admin/ingest-failures",
+			token:          "wrong",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"type":"about:blank","title":"Unauthorized","status":401,"detail":"invalid admin token","instance":"/admin/ingest-failures"}`,
+		},
+		{
+			name:   "list",
+			method: http.MethodGet,
+			target: "/admin/ingest-failures?limit=10&offset=20",
+			mockBehavior: func() {
+				mockIngestFailureService.EXPECT().ListIngestFailures(gomock.Any(), 10, 20).Return([]model.IngestFailure{failure}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[" + failureJSON + "]",
+		},
+		{
+			name:   "list with database down",
+			method: http.MethodGet,
+			target: "/admin/ingest-failures",
+			mockBehavior: func() {
+				mockIngestFailureService.EXPECT().ListIngestFailures(gomock.Any(), 50, 0).Return(nil, apperror.Wrap(apperror.ErrUnavailable, errors.New("dial tcp 10.0.0.5:5432: connection refused")))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"type":"about:blank","title":"Service Unavailable","status":503,"detail":"dependency is temporarily unavailable, retry later","instance":"/admin/ingest-failures"}`,
+		},
+		{
+			name:   "list with internal error",
+			method: http.MethodGet,
+			target: "/admin/ingest-failures",
+			mockBehavior: func() {
+				mockIngestFailureService.EXPECT().ListIngestFailures(gomock.Any(), 50, 0).Return(nil, errors.New("failed to scan ingest_failures row: column payload"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"internal error","instance":"/admin/ingest-failures"}`,
+		},
+		{
+			name:           "list with invalid limit",
+			method:         http.MethodGet,
+			target:         "/admin/ingest-failures?limit=0",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"limit must be between 1 and 500","instance":"/admin/ingest-failures"}`,
+		},
+		{
+			name:   "get",
+			method: http.MethodGet,
+			target: "/admin/ingest-failures/1",
+			mockBehavior: func() {
+				mockIngestFailureService.EXPECT().GetIngestFailure(gomock.Any(), int64(1)).Return(&failure, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   failureJSON,
+		},
+		{
+			name:   "get not found",
+			method: http.MethodGet,
+			target: "/admin/ingest-failures/2",
+			mockBehavior: func() {
+				mockIngestFailureService.EXPECT().GetIngestFailure(gomock.Any(), int64(2)).Return(nil, apperror.Wrap(apperror.ErrNotFound, errors.New("ingest failure 2 not found")))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"type":"about:blank","title":"Not Found","status":404,"detail":"ingest failure 2 not found","instance":"/admin/ingest-failures/2"}`,
+		},
+		{
+			name:   "update payload",
+			method: http.MethodPut,
+			target: "/admin/ingest-failures/1",
+			body:   `{"order_uid":"order_uid1"}`,
+			mockBehavior: func() {
+				updated := failure
+				updated.Payload = `{"order_uid":"order_uid1"}`
+				mockIngestFailureService.EXPECT().UpdateIngestFailure(gomock.Any(), int64(1), `{"order_uid":"order_uid1"}`).Return(&updated, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   strings.Replace(failureJSON, `"payload":"{\"order_uid\":"`, `"payload":"{\"order_uid\":\"order_uid1\"}"`, 1),
+		},
+		{
+			name:   "reprocess",
+			method: http.MethodPost,
+			target: "/admin/ingest-failures/1/reprocess",
+			mockBehavior: func() {
+				mockIngestFailureService.EXPECT().ReprocessIngestFailure(gomock.Any(), int64(1)).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "reprocess invalid order",
+			method: http.MethodPost,
+			target: "/admin/ingest-failures/1/reprocess",
+			mockBehavior: func() {
+				mockIngestFailureService.EXPECT().ReprocessIngestFailure(gomock.Any(), int64(1)).Return(&validation.Error{
+					Violations: []validation.Violation{{Field: "order_uid", Message: "is required"}},
+				})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid order: order_uid: is required","instance":"/admin/ingest-failures/1/reprocess","violations":[{"field":"order_uid","message":"is required"}]}`,
+		},
+		{
+			name:   "reprocess conflicting order",
+			method: http.MethodPost,
+			target: "/admin/ingest-failures/1/reprocess",
+			mockBehavior: func() {
+				mockIngestFailureService.EXPECT().ReprocessIngestFailure(gomock.Any(), int64(1)).Return(fmt.Errorf("failed to reprocess ingest failure 1: %w", repository.ErrOrderConflict))
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `{"type":"about:blank","title":"Conflict","status":409,"detail":"failed to reprocess ingest failure 1: order with the same order_uid and different content already exists: conflict","instance":"/admin/ingest-failures/1/reprocess"}`,
+		},
+		{
+			name:   "discard",
+			method: http.MethodDelete,
+			target: "/admin/ingest-failures/1",
+			mockBehavior: func() {
+				mockIngestFailureService.EXPECT().DiscardIngestFailure(gomock.Any(), int64(1)).Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehavior()
+
+			req, err := http.NewRequest(test.method, test.target, strings.NewReader(test.body))
+			if err != nil {
+				t.Fatalf("error occurred while testing")
+			}
+			token := test.token
+			if token == "" {
+				token = "secret"
+			}
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			if test.expectedBody != "" {
+				assert.JSONEq(t, test.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/karambo3a/wbtech_test_task/internal/bloom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloomFilter(t *testing.T) {
+	const n = 10000
+	filter := bloom.NewFilter(n, 0.01)
+	for i := range n {
+		filter.Add(fmt.Sprintf("order_uid%d", i))
+	}
+
+	// added strings are never reported as absent
+	for i := range n {
+		assert.True(t, filter.Test(fmt.Sprintf("order_uid%d", i)))
+	}
+
+	falsePositives := 0
+	for i := range n {
+		if filter.Test(fmt.Sprintf("unknown_uid%d", i)) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, n*3/100)
+}
@@ -0,0 +1,238 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+	"github.com/karambo3a/wbtech_test_task/internal/cache"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+	mock "github.com/karambo3a/wbtech_test_task/test/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCache := mock.NewMockOrderCache(ctrl)
+	breaker := cache.NewCircuitBreakerCache(mockCache, 2, 50*time.Millisecond)
+
+	unavailable := apperror.Wrap(apperror.ErrUnavailable, errors.New("connection refused"))
+	miss := apperror.Wrap(apperror.ErrNotFound, errors.New("cache miss for key=order_uid1"))
+	ctx := context.Background()
+
+	// cache misses are not failures
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&model.Order{}, time.Time{}, miss)
+	_, _, err := breaker.Get(ctx, "order_uid1")
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+	assert.Equal(t, cache.BreakerClosed, breaker.BreakerStats().State)
+
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&model.Order{}, time.Time{}, unavailable).Times(2)
+	for range 2 {
+		_, _, err = breaker.Get(ctx, "order_uid1")
+		assert.ErrorIs(t, err, apperror.ErrUnavailable)
+	}
+	assert.Equal(t, cache.BreakerOpen, breaker.BreakerStats().State)
+
+	// open breaker rejects calls without reaching the cache
+	_, _, err = breaker.Get(ctx, "order_uid1")
+	assert.ErrorIs(t, err, apperror.ErrUnavailable)
+	assert.ErrorIs(t, breaker.Set(ctx, "order_uid1", &model.Order{}, time.Hour), apperror.ErrUnavailable)
+	assert.Equal(t, int64(2), breaker.BreakerStats().Rejected)
+
+	// failed probe opens the breaker again
+	time.Sleep(60 * time.Millisecond)
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&model.Order{}, time.Time{}, unavailable)
+	_, _, err = breaker.Get(ctx, "order_uid1")
+	assert.ErrorIs(t, err, apperror.ErrUnavailable)
+	assert.Equal(t, cache.BreakerOpen, breaker.BreakerStats().State)
+
+	// successful probe closes it
+	time.Sleep(60 * time.Millisecond)
+	order := &model.Order{OrderUID: "order_uid1"}
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(order, time.Time{}, nil)
+	got, _, err := breaker.Get(ctx, "order_uid1")
+	assert.NoError(t, err)
+	assert.Equal(t, order, got)
+	assert.Equal(t, cache.BreakerClosed, breaker.BreakerStats().State)
+	assert.Equal(t, 0, breaker.BreakerStats().ConsecutiveFailures)
+}
+
+func TestCircuitBreakerCacheCanceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCache := mock.NewMockOrderCache(ctrl)
+	breaker := cache.NewCircuitBreakerCache(mockCache, 1, 50*time.Millisecond)
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	canceled := apperror.Wrap(apperror.ErrUnavailable, fmt.Errorf("failed to get value by key=order_uid1: %w", context.Canceled))
+
+	// the caller went away, the cache is not to blame
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&model.Order{}, time.Time{}, canceled).Times(2)
+	_, _, err := breaker.Get(canceledCtx, "order_uid1")
+	assert.ErrorIs(t, err, context.Canceled)
+	_, _, err = breaker.Get(context.Background(), "order_uid1")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, cache.BreakerClosed, breaker.BreakerStats().State)
+	assert.Equal(t, 0, breaker.BreakerStats().ConsecutiveFailures)
+
+	// a timed out call is a failure
+	timeout := apperror.Wrap(apperror.ErrUnavailable, fmt.Errorf("failed to get value by key=order_uid1: %w", context.DeadlineExceeded))
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&model.Order{}, time.Time{}, timeout)
+	_, _, err = breaker.Get(context.Background(), "order_uid1")
+	assert.ErrorIs(t, err, apperror.ErrUnavailable)
+	assert.Equal(t, cache.BreakerOpen, breaker.BreakerStats().State)
+
+	// a cancelled probe neither closes the breaker nor keeps it half-open
+	time.Sleep(60 * time.Millisecond)
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&model.Order{}, time.Time{}, canceled)
+	_, _, err = breaker.Get(canceledCtx, "order_uid1")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, cache.BreakerOpen, breaker.BreakerStats().State)
+
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&model.Order{}, time.Time{}, nil)
+	_, _, err = breaker.Get(context.Background(), "order_uid1")
+	assert.NoError(t, err)
+	assert.Equal(t, cache.BreakerClosed, breaker.BreakerStats().State)
+}
+
+func TestTieredCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCache := mock.NewMockOrderCache(ctrl)
+	tiered := cache.NewTieredCache(mockCache, 2, 1<<20, 50*time.Millisecond)
+	ctx := context.Background()
+	miss := apperror.Wrap(apperror.ErrNotFound, errors.New("cache miss"))
+
+	order1 := &model.Order{OrderUID: "order_uid1"}
+	order2 := &model.Order{OrderUID: "order_uid2"}
+	order3 := &model.Order{OrderUID: "order_uid3"}
+
+	// the first read goes to redis, the next ones are served from memory with the redis expiration
+	expiresAt := time.Now().Add(time.Hour)
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(order1, expiresAt, nil)
+	for range 3 {
+		got, gotExpiresAt, err := tiered.Get(ctx, "order_uid1")
+		assert.NoError(t, err)
+		assert.Equal(t, order1, got)
+		assert.Equal(t, expiresAt, gotExpiresAt)
+	}
+
+	// misses are not stored in memory
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid2").Return(&model.Order{}, time.Time{}, miss).Times(2)
+	for range 2 {
+		_, _, err := tiered.Get(ctx, "order_uid2")
+		assert.ErrorIs(t, err, apperror.ErrNotFound)
+	}
+
+	// set writes through and replaces the local copy
+	updated := &model.Order{OrderUID: "order_uid1", TrackNumber: "new"}
+	mockCache.EXPECT().Set(gomock.Any(), "order_uid1", updated, time.Hour).Return(nil)
+	assert.NoError(t, tiered.Set(ctx, "order_uid1", updated, time.Hour))
+	got, _, err := tiered.Get(ctx, "order_uid1")
+	assert.NoError(t, err)
+	assert.Equal(t, updated, got)
+
+	// the least recently used order is evicted
+	mockCache.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), time.Hour).Return(nil).Times(2)
+	assert.NoError(t, tiered.Set(ctx, "order_uid2", order2, time.Hour))
+	_, _, err = tiered.Get(ctx, "order_uid1")
+	assert.NoError(t, err)
+	assert.NoError(t, tiered.Set(ctx, "order_uid3", order3, time.Hour))
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid2").Return(order2, time.Time{}, nil)
+	_, _, err = tiered.Get(ctx, "order_uid2")
+	assert.NoError(t, err)
+
+	stats := tiered.Stats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, int64(2), stats.Evictions)
+	// 4 hits in memory and 2 in redis
+	assert.Equal(t, int64(6), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, int64(0), stats.Errors)
+	assert.Positive(t, stats.Bytes)
+
+	// expired orders are read from redis again
+	time.Sleep(60 * time.Millisecond)
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid2").Return(order2, time.Time{}, nil)
+	_, _, err = tiered.Get(ctx, "order_uid2")
+	assert.NoError(t, err)
+}
+
+func TestTieredCacheMaxBytes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCache := mock.NewMockOrderCache(ctrl)
+	order := &model.Order{OrderUID: "order_uid1", Items: make([]model.Item, 10)}
+	tiered := cache.NewTieredCache(mockCache, 100, 200, time.Minute)
+
+	// an order larger than the limit is never kept in memory
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(order, time.Time{}, nil).Times(2)
+	for range 2 {
+		_, _, err := tiered.Get(context.Background(), "order_uid1")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 0, tiered.Stats().Entries)
+	assert.Equal(t, int64(0), tiered.Stats().Bytes)
+}
+
+func TestMemoryCache(t *testing.T) {
+	memory := cache.NewMemoryCache(2, 1<<20)
+	ctx := context.Background()
+
+	order1 := &model.Order{OrderUID: "order_uid1"}
+	order2 := &model.Order{OrderUID: "order_uid2"}
+	order3 := &model.Order{OrderUID: "order_uid3"}
+
+	_, _, err := memory.Get(ctx, "order_uid1")
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+	assert.NotErrorIs(t, err, cache.ErrCachedNotFound)
+
+	assert.NoError(t, memory.SetMany(ctx, []*model.Order{order1, order2}, time.Hour))
+	got, expiresAt, err := memory.Get(ctx, "order_uid1")
+	assert.NoError(t, err)
+	assert.Equal(t, order1, got)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Second)
+
+	// order_uid2 is the least recently used and is evicted
+	assert.NoError(t, memory.Set(ctx, "order_uid3", order3, 0))
+	orders, err := memory.GetMany(ctx, []string{"order_uid1", "order_uid2", "order_uid3"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*model.Order{"order_uid1": order1, "order_uid3": order3}, orders)
+
+	// orders without expiration never expire
+	_, expiresAt, err = memory.Get(ctx, "order_uid3")
+	assert.NoError(t, err)
+	assert.True(t, expiresAt.IsZero())
+
+	// a cached order is not replaced by a not found entry
+	assert.NoError(t, memory.SetNotFound(ctx, "order_uid1", time.Minute))
+	_, _, err = memory.Get(ctx, "order_uid1")
+	assert.NoError(t, err)
+
+	assert.NoError(t, memory.Delete(ctx, "order_uid1"))
+	assert.NoError(t, memory.SetNotFound(ctx, "order_uid1", 50*time.Millisecond))
+	_, _, err = memory.Get(ctx, "order_uid1")
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+	assert.ErrorIs(t, err, cache.ErrCachedNotFound)
+
+	time.Sleep(60 * time.Millisecond)
+	_, _, err = memory.Get(ctx, "order_uid1")
+	assert.NotErrorIs(t, err, cache.ErrCachedNotFound)
+
+	stats := memory.Stats()
+	assert.Equal(t, int64(6), stats.Hits)
+	assert.Equal(t, int64(3), stats.Misses)
+	assert.Equal(t, int64(1), stats.Evictions)
+	assert.Equal(t, 1, stats.Entries)
+	assert.Positive(t, stats.Bytes)
+}
@@ -0,0 +1,171 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/karambo3a/wbtech_test_task/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func setRequiredConfigEnv(t *testing.T) {
+	t.Setenv("DATABASE_HOST", "db")
+	t.Setenv("DATABASE_USER", "postgres")
+	t.Setenv("DATABASE_NAME", "order")
+	t.Setenv("KAFKA_BROKERS_CONS", "kafka:29092")
+	t.Setenv("REDIS", "redis:6379")
+}
+
+func writeConfigFile(t *testing.T, name string, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		setRequiredConfigEnv(t)
+
+		cfg, args, err := config.Load(nil)
+		assert.NoError(t, err)
+		assert.Empty(t, args)
+		assert.Equal(t, "order", cfg.Kafka.Topic)
+		assert.Equal(t, "disable", cfg.Database.SSLMode)
+		assert.Equal(t, 24*time.Hour, cfg.Cache.TTL)
+		assert.Equal(t, []string{"kafka:29092"}, cfg.Kafka.Brokers)
+	})
+
+	t.Run("file < env < flags", func(t *testing.T) {
+		setRequiredConfigEnv(t)
+		path := writeConfigFile(t, "config.yaml", `
+kafka:
+  topic: file-topic
+  workers: 2
+  brokers: [a:9092, b:9092]
+  batch_timeout: 2s
+cache:
+  ttl: 1h
+  warmup_limit: 10
+`)
+		t.Setenv("KAFKA_CONSUMER_WORKERS", "8")
+		t.Setenv("KAFKA_BATCH_TIMEOUT_MS", "250")
+		t.Setenv("CACHE_WARMUP_LIMIT", "20")
+		t.Setenv("CACHE_BLOOM_FP_RATE", "0.001")
+
+		cfg, args, err := config.Load([]string{"-config", path, "-cache.warmup_limit=30", "migrate", "up"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"migrate", "up"}, args)
+		assert.Equal(t, "file-topic", cfg.Kafka.Topic)
+		assert.Equal(t, time.Hour, cfg.Cache.TTL)
+		assert.Equal(t, 8, cfg.Kafka.Workers)
+		assert.Equal(t, 250*time.Millisecond, cfg.Kafka.BatchTimeout)
+		assert.Equal(t, []string{"kafka:29092"}, cfg.Kafka.Brokers)
+		assert.Equal(t, int64(30), cfg.Cache.WarmupLimit)
+		assert.Equal(t, 0.001, cfg.Cache.BloomFPRate)
+	})
+
+	t.Run("json file", func(t *testing.T) {
+		setRequiredConfigEnv(t)
+		t.Setenv("CONFIG_FILE", writeConfigFile(t, "config.json", `{"database": {"sslmode": "require", "migrate_on_start": false}}`))
+
+		cfg, _, err := config.Load(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "require", cfg.Database.SSLMode)
+		assert.False(t, cfg.Database.MigrateOnStart)
+	})
+
+	t.Run("unknown file key", func(t *testing.T) {
+		setRequiredConfigEnv(t)
+		path := writeConfigFile(t, "config.yaml", "kafka:\n  topik: order\n")
+
+		_, _, err := config.Load([]string{"-config", path})
+		assert.ErrorContains(t, err, "unknown key kafka.topik")
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		setRequiredConfigEnv(t)
+		t.Setenv("REDIS", "")
+		t.Setenv("DATABASE_SSLMODE", "off")
+		t.Setenv("CACHE_WARMUP_STRATEGY", "popular")
+		t.Setenv("REDIS_TLS_CA_FILE", "/etc/redis/ca.pem")
+		t.Setenv("KAFKA_PROCESS_TIMEOUT_MS", "20000")
+
+		_, _, err := config.Load([]string{"-kafka.workers=0"})
+		assert.ErrorContains(t, err, "redis.addr is required")
+		assert.ErrorContains(t, err, "database.sslmode must be one of")
+		assert.ErrorContains(t, err, "kafka.workers must be positive")
+		assert.ErrorContains(t, err, "cache.warmup_strategy must be one of recent, frequent")
+		assert.ErrorContains(t, err, "redis.tls_ca_file requires redis.tls")
+		assert.ErrorContains(t, err, "kafka.process_timeout must be shorter than server.shutdown_timeout")
+	})
+
+	t.Run("memory cache backend", func(t *testing.T) {
+		setRequiredConfigEnv(t)
+		t.Setenv("REDIS", "")
+		t.Setenv("CACHE_BACKEND", "memory")
+
+		cfg, _, err := config.Load(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "memory", cfg.Cache.Backend)
+
+		_, _, err = config.Load([]string{"-cache.local_max_entries=0"})
+		assert.ErrorContains(t, err, "cache.local_max_entries must be positive for the memory backend")
+	})
+
+	t.Run("command needs only database", func(t *testing.T) {
+		t.Setenv("DATABASE_HOST", "db")
+		t.Setenv("DATABASE_USER", "postgres")
+		t.Setenv("DATABASE_NAME", "order")
+		t.Setenv("KAFKA_BROKERS_CONS", "")
+		t.Setenv("REDIS", "")
+
+		cfg, args, err := config.Load([]string{"migrate", "status"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"migrate", "status"}, args)
+		assert.Equal(t, "db", cfg.Database.Host)
+
+		// the service itself still needs Kafka and Redis
+		_, _, err = config.Load(nil)
+		assert.ErrorContains(t, err, "kafka.brokers is required")
+		assert.ErrorContains(t, err, "redis.addr is required")
+
+		t.Setenv("DATABASE_HOST", "")
+		_, _, err = config.Load([]string{"migrate", "status"})
+		assert.ErrorContains(t, err, "database.host is required")
+	})
+
+	t.Run("sections validated separately", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Database.Host, cfg.Database.User, cfg.Database.Name = "db", "postgres", "order"
+
+		assert.NoError(t, cfg.Database.Validate())
+		assert.NoError(t, cfg.Log.Validate())
+		assert.NoError(t, cfg.Cache.Validate())
+		err := cfg.Kafka.Validate()
+		assert.ErrorContains(t, err, "kafka.brokers is required")
+		assert.NotContains(t, err.Error(), "database")
+		assert.ErrorContains(t, cfg.Validate(), "redis.addr is required")
+	})
+
+	t.Run("secrets redacted", func(t *testing.T) {
+		setRequiredConfigEnv(t)
+		t.Setenv("DATABASE_PASSWORD", "p4ssw0rd")
+		t.Setenv("ADMIN_TOKEN", "t0ken")
+		t.Setenv("REDIS_PASSWORD", "r3dis")
+		t.Setenv("REDIS_PASSWORD", "r3dis")
+
+		cfg, _, err := config.Load(nil)
+		assert.NoError(t, err)
+		printed := cfg.String()
+		assert.NotContains(t, printed, "p4ssw0rd")
+		assert.NotContains(t, printed, "t0ken")
+		assert.NotContains(t, printed, "r3dis")
+		assert.NotContains(t, printed, "r3dis")
+		assert.Contains(t, printed, "database.password=******\n")
+		assert.Contains(t, printed, "kafka.topic=order\n")
+	})
+}
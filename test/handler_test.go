@@ -10,7 +10,9 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
 	"github.com/karambo3a/wbtech_test_task/internal/handlers"
+	"github.com/karambo3a/wbtech_test_task/internal/health"
 	"github.com/karambo3a/wbtech_test_task/internal/model"
 	"github.com/karambo3a/wbtech_test_task/internal/service"
 	mock "github.com/karambo3a/wbtech_test_task/test/mocks"
@@ -23,7 +25,7 @@ func TestHandlerGetOrder(t *testing.T) {
 
 	mockGetOrderService := mock.NewMockOrderServiceInterface(ctrl)
 	mockService := &service.Service{OrderServiceInterface: mockGetOrderService}
-	h := handlers.NewHandler(mockService)
+	h := handlers.NewHandler(mockService, health.NewHealth(time.Second), "")
 
 	testOrder := model.Order{
 		OrderUID:          "order_uid1",
@@ -87,7 +89,7 @@ func TestHandlerGetOrder(t *testing.T) {
 			name:     "success",
 			orderUID: "order_uid1",
 			mockBehavior: func() {
-				mockGetOrderService.EXPECT().GetOrder("order_uid1").Return(testOrder, nil)
+				mockGetOrderService.EXPECT().GetOrder(gomock.Any(), "order_uid1").Return(&testOrder, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody:   expectedJSON,
@@ -96,10 +98,28 @@ func TestHandlerGetOrder(t *testing.T) {
 			name:     "not found",
 			orderUID: "order_not_found",
 			mockBehavior: func() {
-				mockGetOrderService.EXPECT().GetOrder("order_not_found").Return(model.Order{}, errors.New("order not found"))
+				mockGetOrderService.EXPECT().GetOrder(gomock.Any(), "order_not_found").Return(&model.Order{}, apperror.Wrap(apperror.ErrNotFound, errors.New("order order_not_found not found")))
 			},
 			expectedStatus: http.StatusNotFound,
-			expectedBody:   `{"error":"order not found"}`,
+			expectedBody:   `{"type":"about:blank","title":"Not Found","status":404,"detail":"order order_not_found not found","instance":"/order/order_not_found"}`,
+		},
+		{
+			name:     "storage unavailable",
+			orderUID: "order_uid1",
+			mockBehavior: func() {
+				mockGetOrderService.EXPECT().GetOrder(gomock.Any(), "order_uid1").Return(&model.Order{}, apperror.Wrap(apperror.ErrUnavailable, errors.New("connection refused")))
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"type":"about:blank","title":"Service Unavailable","status":503,"detail":"dependency is temporarily unavailable, retry later","instance":"/order/order_uid1"}`,
+		},
+		{
+			name:     "internal error",
+			orderUID: "order_uid1",
+			mockBehavior: func() {
+				mockGetOrderService.EXPECT().GetOrder(gomock.Any(), "order_uid1").Return(&model.Order{}, errors.New("unexpected"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"internal error","instance":"/order/order_uid1"}`,
 		},
 	}
 
@@ -124,3 +144,72 @@ func TestHandlerGetOrder(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerListOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mock.NewMockOrderServiceInterface(ctrl)
+	mockService := &service.Service{OrderServiceInterface: mockOrderService}
+	h := handlers.NewHandler(mockService, health.NewHealth(time.Second), "")
+
+	tests := []struct {
+		name           string
+		query          string
+		mockBehavior   func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:  "filters and cursor",
+			query: "?customer_id=c1&currency=USD&provider=wbpay&created_from=2021-01-01T00:00:00Z&cursor=abc&limit=10",
+			mockBehavior: func() {
+				filter := model.OrderFilter{
+					CustomerID:  "c1",
+					Currency:    "USD",
+					Provider:    "wbpay",
+					CreatedFrom: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+				}
+				mockOrderService.EXPECT().ListOrders(gomock.Any(), filter, "abc", 10).Return(&model.OrderPage{Orders: []*model.Order{}, NextCursor: "next"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"orders":[],"next_cursor":"next"}`,
+		},
+		{
+			name:           "invalid limit",
+			query:          "?limit=0",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"limit must be between 1 and 500","instance":"/orders"}`,
+		},
+		{
+			name:           "invalid date",
+			query:          "?created_to=yesterday",
+			mockBehavior:   func() {},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"created_to must be an RFC 3339 time","instance":"/orders"}`,
+		},
+		{
+			name:  "invalid cursor",
+			query: "?cursor=bad",
+			mockBehavior: func() {
+				mockOrderService.EXPECT().ListOrders(gomock.Any(), model.OrderFilter{}, "bad", 50).Return(nil, apperror.Wrap(apperror.ErrInvalidInput, errors.New("invalid cursor")))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid cursor","instance":"/orders"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehavior()
+
+			req := httptest.NewRequest(http.MethodGet, "/orders"+test.query, nil)
+			w := httptest.NewRecorder()
+			h.InitRouts().ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			assert.JSONEq(t, test.expectedBody, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/karambo3a/wbtech_test_task/internal/handlers"
+	"github.com/karambo3a/wbtech_test_task/internal/health"
+	"github.com/karambo3a/wbtech_test_task/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCheck(name string, critical bool, err error) health.Check {
+	return health.Check{
+		Name:     name,
+		Critical: critical,
+		Run: func(ctx context.Context) (map[string]any, error) {
+			return map[string]any{"name": name}, err
+		},
+	}
+}
+
+func TestHealthCheck(t *testing.T) {
+	down := errors.New("connection refused")
+
+	tests := []struct {
+		name   string
+		checks []health.Check
+		status string
+	}{
+		{
+			name:   "all ok",
+			checks: []health.Check{newCheck("postgres", true, nil), newCheck("redis", false, nil)},
+			status: health.StatusOK,
+		},
+		{
+			name:   "non-critical failing",
+			checks: []health.Check{newCheck("postgres", true, nil), newCheck("redis", false, down)},
+			status: health.StatusDegraded,
+		},
+		{
+			name:   "critical failing",
+			checks: []health.Check{newCheck("postgres", true, down), newCheck("redis", false, down)},
+			status: health.StatusFailing,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := health.NewHealth(time.Second)
+			for _, check := range test.checks {
+				h.Add(check)
+			}
+
+			report := h.Check(context.Background())
+			assert.Equal(t, test.status, report.Status)
+			assert.Len(t, report.Checks, len(test.checks))
+			for _, check := range test.checks {
+				assert.Equal(t, check.Name, report.Checks[check.Name].Details["name"])
+			}
+		})
+	}
+
+	t.Run("check timeout", func(t *testing.T) {
+		h := health.NewHealth(20 * time.Millisecond)
+		h.Add(health.Check{
+			Name:     "postgres",
+			Critical: true,
+			Run: func(ctx context.Context) (map[string]any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		})
+
+		report := h.Check(context.Background())
+		assert.Equal(t, health.StatusFailing, report.Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["postgres"].Error)
+		assert.GreaterOrEqual(t, report.Checks["postgres"].LatencyMS, float64(20))
+	})
+
+	t.Run("shutting down", func(t *testing.T) {
+		h := health.NewHealth(time.Second)
+		h.Add(newCheck("postgres", true, nil))
+		h.SetShuttingDown()
+
+		report := h.Check(context.Background())
+		assert.Equal(t, health.StatusShuttingDown, report.Status)
+		assert.False(t, report.Ready())
+	})
+}
+
+func TestHandlerHealth(t *testing.T) {
+	h := health.NewHealth(time.Second)
+	h.Add(newCheck("postgres", true, nil))
+	h.Add(newCheck("redis", false, errors.New("connection refused")))
+	router := handlers.NewHandler(&service.Service{}, h, "").InitRouts()
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var report health.Report
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+	assert.Equal(t, health.StatusDegraded, report.Status)
+	assert.Equal(t, health.StatusFailing, report.Checks["redis"].Status)
+	assert.Equal(t, "connection refused", report.Checks["redis"].Error)
+
+	h.SetShuttingDown()
+	req = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	// liveness does not depend on readiness
+	req = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/karambo3a/wbtech_test_task/internal/config"
+	"github.com/karambo3a/wbtech_test_task/internal/handlers"
+	"github.com/karambo3a/wbtech_test_task/internal/health"
+	"github.com/karambo3a/wbtech_test_task/internal/logger"
+	"github.com/karambo3a/wbtech_test_task/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := logger.New(&buf, config.LogConfig{Level: "info", Format: "json"})
+	assert.NoError(t, err)
+
+	ctx := logger.WithCorrelationID(logger.WithRequestID(context.Background(), "req-1"), "order-1")
+	log.DebugContext(ctx, "skipped below level")
+	log.With("component", "test").InfoContext(ctx, "order saved", "order_uid", "order-1", logger.Err(errors.New("boom")))
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "order saved", record["msg"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "order-1", record["correlation_id"])
+	assert.Equal(t, "test", record["component"])
+	assert.Equal(t, "boom", record["error"])
+
+	_, err = logger.New(&buf, config.LogConfig{Level: "verbose", Format: "json"})
+	assert.Error(t, err)
+	_, err = logger.New(&buf, config.LogConfig{Level: "info", Format: "xml"})
+	assert.Error(t, err)
+}
+
+func TestHandlerRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := logger.New(&buf, config.LogConfig{Level: "info", Format: "json"})
+	assert.NoError(t, err)
+	defaultLogger := slog.Default()
+	slog.SetDefault(log)
+	defer slog.SetDefault(defaultLogger)
+
+	router := handlers.NewHandler(&service.Service{}, health.NewHealth(time.Second), "").InitRouts()
+
+	// the ID sent by the client is kept
+	req := httptest.NewRequest(http.MethodGet, "/orders?limit=0", nil)
+	req.Header.Set("X-Request-Id", "client-id")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, "client-id", rr.Header().Get("X-Request-Id"))
+
+	record := findLogRecord(t, &buf, "http request")
+	assert.Equal(t, "client-id", record["request_id"])
+	assert.Equal(t, float64(http.StatusBadRequest), record["status"])
+
+	// otherwise a new one is generated
+	req = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.NotEmpty(t, rr.Header().Get("X-Request-Id"))
+}
+
+func findLogRecord(t *testing.T, buf *bytes.Buffer, msg string) map[string]any {
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var record map[string]any
+		if !assert.NoError(t, decoder.Decode(&record)) {
+			break
+		}
+		if record["msg"] == msg {
+			return record
+		}
+	}
+	t.Fatalf("no log record %q", msg)
+	return nil
+}
@@ -0,0 +1,43 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/karambo3a/wbtech_test_task/internal/handlers"
+	"github.com/karambo3a/wbtech_test_task/internal/health"
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+	"github.com/karambo3a/wbtech_test_task/internal/service"
+	mock "github.com/karambo3a/wbtech_test_task/test/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mock.NewMockOrderServiceInterface(ctrl)
+	mockService := &service.Service{OrderServiceInterface: mockOrderService}
+	router := handlers.NewHandler(mockService, health.NewHealth(time.Second), "").InitRouts()
+
+	mockOrderService.EXPECT().GetOrder(gomock.Any(), "order_uid1").Return(&model.Order{OrderUID: "order_uid1"}, nil)
+	req := httptest.NewRequest(http.MethodGet, "/order/order_uid1", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, "/unknown", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	body := rr.Body.String()
+	// requests are labeled with the route pattern, not the path
+	assert.Contains(t, body, `orders_http_requests_total{method="GET",route="/order/{order_uid}",status="200"}`)
+	assert.Contains(t, body, `orders_http_requests_total{method="GET",route="unmatched",status="404"}`)
+	assert.NotContains(t, body, "order_uid1")
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/karambo3a/wbtech_test_task/internal/migrator"
+	"github.com/karambo3a/wbtech_test_task/migrations"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("embedded migrations", func(t *testing.T) {
+		loaded, err := migrator.LoadMigrations(migrations.FS)
+		assert.NoError(t, err)
+		for i, migration := range loaded {
+			assert.Equal(t, int64(i+1), migration.Version)
+			assert.NotEmpty(t, migration.Up)
+			assert.NotEmpty(t, migration.Down)
+		}
+	})
+
+	tests := []struct {
+		name        string
+		fsys        fstest.MapFS
+		expectedErr string
+	}{
+		{
+			name: "ordered by version",
+			fsys: fstest.MapFS{
+				"0010_b.up.sql":   {Data: []byte("b up")},
+				"0010_b.down.sql": {Data: []byte("b down")},
+				"0002_a.up.sql":   {Data: []byte("a up")},
+				"0002_a.down.sql": {Data: []byte("a down")},
+			},
+		},
+		{
+			name: "missing down",
+			fsys: fstest.MapFS{
+				"0001_a.up.sql": {Data: []byte("a up")},
+			},
+			expectedErr: "migration 1_a must have both up and down files",
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"0001_a.up.sql":   {Data: []byte("a up")},
+				"0001_b.down.sql": {Data: []byte("b down")},
+			},
+			expectedErr: "migration version 1 is used by a and b",
+		},
+		{
+			name: "invalid name",
+			fsys: fstest.MapFS{
+				"init.sql": {Data: []byte("init")},
+			},
+			expectedErr: "invalid migration file name init.sql",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loaded, err := migrator.LoadMigrations(test.fsys)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, []migrator.Migration{
+				{Version: 2, Name: "a", Up: "a up", Down: "a down"},
+				{Version: 10, Name: "b", Up: "b up", Down: "b down"},
+			}, loaded)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: cache.go
+
+// Package mock_cache is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	cache "github.com/karambo3a/wbtech_test_task/internal/cache"
+	model "github.com/karambo3a/wbtech_test_task/internal/model"
+)
+
+// MockOrderCache is a mock of OrderCache interface.
+type MockOrderCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderCacheMockRecorder
+}
+
+// MockOrderCacheMockRecorder is the mock recorder for MockOrderCache.
+type MockOrderCacheMockRecorder struct {
+	mock *MockOrderCache
+}
+
+// NewMockOrderCache creates a new mock instance.
+func NewMockOrderCache(ctrl *gomock.Controller) *MockOrderCache {
+	mock := &MockOrderCache{ctrl: ctrl}
+	mock.recorder = &MockOrderCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderCache) EXPECT() *MockOrderCacheMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method.
+func (m *MockOrderCache) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockOrderCacheMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockOrderCache)(nil).Close))
+}
+
+// Delete mocks base method.
+func (m *MockOrderCache) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockOrderCacheMockRecorder) Delete(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockOrderCache)(nil).Delete), ctx, key)
+}
+
+// Get mocks base method.
+func (m *MockOrderCache) Get(ctx context.Context, key string) (*model.Order, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(*model.Order)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockOrderCacheMockRecorder) Get(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockOrderCache)(nil).Get), ctx, key)
+}
+
+// GetMany mocks base method.
+func (m *MockOrderCache) GetMany(ctx context.Context, keys []string) (map[string]*model.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMany", ctx, keys)
+	ret0, _ := ret[0].(map[string]*model.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMany indicates an expected call of GetMany.
+func (mr *MockOrderCacheMockRecorder) GetMany(ctx, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMany", reflect.TypeOf((*MockOrderCache)(nil).GetMany), ctx, keys)
+}
+
+// Ping mocks base method.
+func (m *MockOrderCache) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockOrderCacheMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockOrderCache)(nil).Ping), ctx)
+}
+
+// Set mocks base method.
+func (m *MockOrderCache) Set(ctx context.Context, key string, value *model.Order, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, key, value, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockOrderCacheMockRecorder) Set(ctx, key, value, expiration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockOrderCache)(nil).Set), ctx, key, value, expiration)
+}
+
+// SetMany mocks base method.
+func (m *MockOrderCache) SetMany(ctx context.Context, orders []*model.Order, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMany", ctx, orders, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMany indicates an expected call of SetMany.
+func (mr *MockOrderCacheMockRecorder) SetMany(ctx, orders, expiration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMany", reflect.TypeOf((*MockOrderCache)(nil).SetMany), ctx, orders, expiration)
+}
+
+// SetNotFound mocks base method.
+func (m *MockOrderCache) SetNotFound(ctx context.Context, key string, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNotFound", ctx, key, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetNotFound indicates an expected call of SetNotFound.
+func (mr *MockOrderCacheMockRecorder) SetNotFound(ctx, key, expiration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNotFound", reflect.TypeOf((*MockOrderCache)(nil).SetNotFound), ctx, key, expiration)
+}
+
+// Stats mocks base method.
+func (m *MockOrderCache) Stats() cache.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(cache.Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockOrderCacheMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockOrderCache)(nil).Stats))
+}
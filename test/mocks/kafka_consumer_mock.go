@@ -5,9 +5,11 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	consumer "github.com/karambo3a/wbtech_test_task/internal/consumer"
 )
 
 // MockConsumer is a mock of Consumer interface.
@@ -34,21 +36,61 @@ func (m *MockConsumer) EXPECT() *MockConsumerMockRecorder {
 }
 
 // Close mocks base method.
-func (m *MockConsumer) Close() error {
+func (m *MockConsumer) Close(ctx context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Close")
+	ret := m.ctrl.Call(m, "Close", ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Close indicates an expected call of Close.
-func (mr *MockConsumerMockRecorder) Close() *gomock.Call {
+func (mr *MockConsumerMockRecorder) Close(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockConsumer)(nil).Close))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockConsumer)(nil).Close), ctx)
+}
+
+// Lag mocks base method.
+func (m *MockConsumer) Lag() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lag")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// Lag indicates an expected call of Lag.
+func (mr *MockConsumerMockRecorder) Lag() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lag", reflect.TypeOf((*MockConsumer)(nil).Lag))
+}
+
+// Ping mocks base method.
+func (m *MockConsumer) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockConsumerMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockConsumer)(nil).Ping), ctx)
+}
+
+// StartBatchConsuming mocks base method.
+func (m *MockConsumer) StartBatchConsuming(processBatchFunc func(context.Context, []consumer.Message) []error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "StartBatchConsuming", processBatchFunc)
+}
+
+// StartBatchConsuming indicates an expected call of StartBatchConsuming.
+func (mr *MockConsumerMockRecorder) StartBatchConsuming(processBatchFunc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartBatchConsuming", reflect.TypeOf((*MockConsumer)(nil).StartBatchConsuming), processBatchFunc)
 }
 
 // StartConsuming mocks base method.
-func (m *MockConsumer) StartConsuming(processFunc func([]byte) error) {
+func (m *MockConsumer) StartConsuming(processFunc func(context.Context, consumer.Message) error) {
 	m.ctrl.T.Helper()
 	m.ctrl.Call(m, "StartConsuming", processFunc)
 }
@@ -1,80 +0,0 @@
-// Code generated by MockGen. DO NOT EDIT.
-// Source: redis_cache.go
-
-// Package mock_cache is a generated GoMock package.
-package mock
-
-import (
-	context "context"
-	reflect "reflect"
-	time "time"
-
-	gomock "github.com/golang/mock/gomock"
-	model "github.com/karambo3a/wbtech_test_task/internal/model"
-)
-
-// MockRedisCache is a mock of RedisCache interface.
-type MockRedisCache struct {
-	ctrl     *gomock.Controller
-	recorder *MockRedisCacheMockRecorder
-}
-
-// MockRedisCacheMockRecorder is the mock recorder for MockRedisCache.
-type MockRedisCacheMockRecorder struct {
-	mock *MockRedisCache
-}
-
-// NewMockRedisCache creates a new mock instance.
-func NewMockRedisCache(ctrl *gomock.Controller) *MockRedisCache {
-	mock := &MockRedisCache{ctrl: ctrl}
-	mock.recorder = &MockRedisCacheMockRecorder{mock}
-	return mock
-}
-
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockRedisCache) EXPECT() *MockRedisCacheMockRecorder {
-	return m.recorder
-}
-
-// Get mocks base method.
-func (m *MockRedisCache) Get(ctx context.Context, key string) (*model.Order, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", ctx, key)
-	ret0, _ := ret[0].(*model.Order)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// Get indicates an expected call of Get.
-func (mr *MockRedisCacheMockRecorder) Get(ctx, key interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRedisCache)(nil).Get), ctx, key)
-}
-
-// Init mocks base method.
-func (m *MockRedisCache) Init(orders []*model.Order) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Init", orders)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// Init indicates an expected call of Init.
-func (mr *MockRedisCacheMockRecorder) Init(orders interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockRedisCache)(nil).Init), orders)
-}
-
-// Set mocks base method.
-func (m *MockRedisCache) Set(ctx context.Context, key string, value *model.Order, expiration time.Duration) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Set", ctx, key, value, expiration)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// Set indicates an expected call of Set.
-func (mr *MockRedisCacheMockRecorder) Set(ctx, key, value, expiration interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockRedisCache)(nil).Set), ctx, key, value, expiration)
-}
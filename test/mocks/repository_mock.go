@@ -5,10 +5,12 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
 	model "github.com/karambo3a/wbtech_test_task/internal/model"
+	repository "github.com/karambo3a/wbtech_test_task/internal/repository"
 )
 
 // MockOrderRepositoryInterface is a mock of OrderRepositoryInterface interface.
@@ -34,46 +36,231 @@ func (m *MockOrderRepositoryInterface) EXPECT() *MockOrderRepositoryInterfaceMoc
 	return m.recorder
 }
 
-// GetAllOrders mocks base method.
-func (m *MockOrderRepositoryInterface) GetAllOrders(limit int64) ([]*model.Order, error) {
+// AddOrderAccesses mocks base method.
+func (m *MockOrderRepositoryInterface) AddOrderAccesses(ctx context.Context, hits map[string]int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllOrders", limit)
+	ret := m.ctrl.Call(m, "AddOrderAccesses", ctx, hits)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddOrderAccesses indicates an expected call of AddOrderAccesses.
+func (mr *MockOrderRepositoryInterfaceMockRecorder) AddOrderAccesses(ctx, hits interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrderAccesses", reflect.TypeOf((*MockOrderRepositoryInterface)(nil).AddOrderAccesses), ctx, hits)
+}
+
+// ForEachOrderUID mocks base method.
+func (m *MockOrderRepositoryInterface) ForEachOrderUID(ctx context.Context, fn func(string)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEachOrderUID", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachOrderUID indicates an expected call of ForEachOrderUID.
+func (mr *MockOrderRepositoryInterfaceMockRecorder) ForEachOrderUID(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachOrderUID", reflect.TypeOf((*MockOrderRepositoryInterface)(nil).ForEachOrderUID), ctx, fn)
+}
+
+// GetMostAccessedOrders mocks base method.
+func (m *MockOrderRepositoryInterface) GetMostAccessedOrders(ctx context.Context, offset, limit int64) ([]*model.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMostAccessedOrders", ctx, offset, limit)
 	ret0, _ := ret[0].([]*model.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllOrders indicates an expected call of GetAllOrders.
-func (mr *MockOrderRepositoryInterfaceMockRecorder) GetAllOrders(limit interface{}) *gomock.Call {
+// GetMostAccessedOrders indicates an expected call of GetMostAccessedOrders.
+func (mr *MockOrderRepositoryInterfaceMockRecorder) GetMostAccessedOrders(ctx, offset, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllOrders", reflect.TypeOf((*MockOrderRepositoryInterface)(nil).GetAllOrders), limit)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMostAccessedOrders", reflect.TypeOf((*MockOrderRepositoryInterface)(nil).GetMostAccessedOrders), ctx, offset, limit)
 }
 
 // GetOrder mocks base method.
-func (m *MockOrderRepositoryInterface) GetOrder(orderUID string) (*model.Order, error) {
+func (m *MockOrderRepositoryInterface) GetOrder(ctx context.Context, orderUID string) (*model.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrder", orderUID)
+	ret := m.ctrl.Call(m, "GetOrder", ctx, orderUID)
 	ret0, _ := ret[0].(*model.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOrder indicates an expected call of GetOrder.
-func (mr *MockOrderRepositoryInterfaceMockRecorder) GetOrder(orderUID interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryInterfaceMockRecorder) GetOrder(ctx, orderUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrderRepositoryInterface)(nil).GetOrder), ctx, orderUID)
+}
+
+// GetRecentOrders mocks base method.
+func (m *MockOrderRepositoryInterface) GetRecentOrders(ctx context.Context, offset, limit int64) ([]*model.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecentOrders", ctx, offset, limit)
+	ret0, _ := ret[0].([]*model.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecentOrders indicates an expected call of GetRecentOrders.
+func (mr *MockOrderRepositoryInterfaceMockRecorder) GetRecentOrders(ctx, offset, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentOrders", reflect.TypeOf((*MockOrderRepositoryInterface)(nil).GetRecentOrders), ctx, offset, limit)
+}
+
+// ListOrders mocks base method.
+func (m *MockOrderRepositoryInterface) ListOrders(ctx context.Context, filter model.OrderFilter, after *model.OrderCursor, limit int) ([]*model.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrders", ctx, filter, after, limit)
+	ret0, _ := ret[0].([]*model.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockOrderRepositoryInterfaceMockRecorder) ListOrders(ctx, filter, after, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrderRepositoryInterface)(nil).GetOrder), orderUID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockOrderRepositoryInterface)(nil).ListOrders), ctx, filter, after, limit)
 }
 
 // SaveOrder mocks base method.
-func (m *MockOrderRepositoryInterface) SaveOrder(order *model.Order) error {
+func (m *MockOrderRepositoryInterface) SaveOrder(ctx context.Context, order *model.Order) (repository.SaveResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOrder", ctx, order)
+	ret0, _ := ret[0].(repository.SaveResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveOrder indicates an expected call of SaveOrder.
+func (mr *MockOrderRepositoryInterfaceMockRecorder) SaveOrder(ctx, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrder", reflect.TypeOf((*MockOrderRepositoryInterface)(nil).SaveOrder), ctx, order)
+}
+
+// SaveOrders mocks base method.
+func (m *MockOrderRepositoryInterface) SaveOrders(ctx context.Context, orders []*model.Order) ([]repository.SaveResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOrders", ctx, orders)
+	ret0, _ := ret[0].([]repository.SaveResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveOrders indicates an expected call of SaveOrders.
+func (mr *MockOrderRepositoryInterfaceMockRecorder) SaveOrders(ctx, orders interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrders", reflect.TypeOf((*MockOrderRepositoryInterface)(nil).SaveOrders), ctx, orders)
+}
+
+// MockIngestFailureRepositoryInterface is a mock of IngestFailureRepositoryInterface interface.
+type MockIngestFailureRepositoryInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockIngestFailureRepositoryInterfaceMockRecorder
+}
+
+// MockIngestFailureRepositoryInterfaceMockRecorder is the mock recorder for MockIngestFailureRepositoryInterface.
+type MockIngestFailureRepositoryInterfaceMockRecorder struct {
+	mock *MockIngestFailureRepositoryInterface
+}
+
+// NewMockIngestFailureRepositoryInterface creates a new mock instance.
+func NewMockIngestFailureRepositoryInterface(ctrl *gomock.Controller) *MockIngestFailureRepositoryInterface {
+	mock := &MockIngestFailureRepositoryInterface{ctrl: ctrl}
+	mock.recorder = &MockIngestFailureRepositoryInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIngestFailureRepositoryInterface) EXPECT() *MockIngestFailureRepositoryInterfaceMockRecorder {
+	return m.recorder
+}
+
+// DeleteFailure mocks base method.
+func (m *MockIngestFailureRepositoryInterface) DeleteFailure(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveOrder", order)
+	ret := m.ctrl.Call(m, "DeleteFailure", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SaveOrder indicates an expected call of SaveOrder.
-func (mr *MockOrderRepositoryInterfaceMockRecorder) SaveOrder(order interface{}) *gomock.Call {
+// DeleteFailure indicates an expected call of DeleteFailure.
+func (mr *MockIngestFailureRepositoryInterfaceMockRecorder) DeleteFailure(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFailure", reflect.TypeOf((*MockIngestFailureRepositoryInterface)(nil).DeleteFailure), ctx, id)
+}
+
+// GetFailure mocks base method.
+func (m *MockIngestFailureRepositoryInterface) GetFailure(ctx context.Context, id int64) (*model.IngestFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFailure", ctx, id)
+	ret0, _ := ret[0].(*model.IngestFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFailure indicates an expected call of GetFailure.
+func (mr *MockIngestFailureRepositoryInterfaceMockRecorder) GetFailure(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFailure", reflect.TypeOf((*MockIngestFailureRepositoryInterface)(nil).GetFailure), ctx, id)
+}
+
+// ListFailures mocks base method.
+func (m *MockIngestFailureRepositoryInterface) ListFailures(ctx context.Context, limit, offset int) ([]model.IngestFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFailures", ctx, limit, offset)
+	ret0, _ := ret[0].([]model.IngestFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFailures indicates an expected call of ListFailures.
+func (mr *MockIngestFailureRepositoryInterfaceMockRecorder) ListFailures(ctx, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFailures", reflect.TypeOf((*MockIngestFailureRepositoryInterface)(nil).ListFailures), ctx, limit, offset)
+}
+
+// SaveFailure mocks base method.
+func (m *MockIngestFailureRepositoryInterface) SaveFailure(ctx context.Context, failure *model.IngestFailure) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFailure", ctx, failure)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveFailure indicates an expected call of SaveFailure.
+func (mr *MockIngestFailureRepositoryInterfaceMockRecorder) SaveFailure(ctx, failure interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFailure", reflect.TypeOf((*MockIngestFailureRepositoryInterface)(nil).SaveFailure), ctx, failure)
+}
+
+// UpdateFailureError mocks base method.
+func (m *MockIngestFailureRepositoryInterface) UpdateFailureError(ctx context.Context, id int64, failureErr string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFailureError", ctx, id, failureErr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFailureError indicates an expected call of UpdateFailureError.
+func (mr *MockIngestFailureRepositoryInterfaceMockRecorder) UpdateFailureError(ctx, id, failureErr interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFailureError", reflect.TypeOf((*MockIngestFailureRepositoryInterface)(nil).UpdateFailureError), ctx, id, failureErr)
+}
+
+// UpdateFailurePayload mocks base method.
+func (m *MockIngestFailureRepositoryInterface) UpdateFailurePayload(ctx context.Context, id int64, payload string) (*model.IngestFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFailurePayload", ctx, id, payload)
+	ret0, _ := ret[0].(*model.IngestFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFailurePayload indicates an expected call of UpdateFailurePayload.
+func (mr *MockIngestFailureRepositoryInterfaceMockRecorder) UpdateFailurePayload(ctx, id, payload interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrder", reflect.TypeOf((*MockOrderRepositoryInterface)(nil).SaveOrder), order)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFailurePayload", reflect.TypeOf((*MockIngestFailureRepositoryInterface)(nil).UpdateFailurePayload), ctx, id, payload)
 }
@@ -5,10 +5,13 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	consumer "github.com/karambo3a/wbtech_test_task/internal/consumer"
 	model "github.com/karambo3a/wbtech_test_task/internal/model"
+	service "github.com/karambo3a/wbtech_test_task/internal/service"
 )
 
 // MockOrderServiceInterface is a mock of OrderServiceInterface interface.
@@ -34,43 +37,184 @@ func (m *MockOrderServiceInterface) EXPECT() *MockOrderServiceInterfaceMockRecor
 	return m.recorder
 }
 
-// CloseConsumer mocks base method.
-func (m *MockOrderServiceInterface) CloseConsumer() {
+// GetOrder mocks base method.
+func (m *MockOrderServiceInterface) GetOrder(ctx context.Context, orderUID string) (*model.Order, error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "CloseConsumer")
+	ret := m.ctrl.Call(m, "GetOrder", ctx, orderUID)
+	ret0, _ := ret[0].(*model.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// CloseConsumer indicates an expected call of CloseConsumer.
-func (mr *MockOrderServiceInterfaceMockRecorder) CloseConsumer() *gomock.Call {
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockOrderServiceInterfaceMockRecorder) GetOrder(ctx, orderUID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseConsumer", reflect.TypeOf((*MockOrderServiceInterface)(nil).CloseConsumer))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrderServiceInterface)(nil).GetOrder), ctx, orderUID)
 }
 
-// GetOrder mocks base method.
-func (m *MockOrderServiceInterface) GetOrder(orderUID string) (*model.Order, error) {
+// ListOrders mocks base method.
+func (m *MockOrderServiceInterface) ListOrders(ctx context.Context, filter model.OrderFilter, cursor string, limit int) (*model.OrderPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrder", orderUID)
-	ret0, _ := ret[0].(*model.Order)
+	ret := m.ctrl.Call(m, "ListOrders", ctx, filter, cursor, limit)
+	ret0, _ := ret[0].(*model.OrderPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetOrder indicates an expected call of GetOrder.
-func (mr *MockOrderServiceInterfaceMockRecorder) GetOrder(orderUID interface{}) *gomock.Call {
+// ListOrders indicates an expected call of ListOrders.
+func (mr *MockOrderServiceInterfaceMockRecorder) ListOrders(ctx, filter, cursor, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrderServiceInterface)(nil).GetOrder), orderUID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrders", reflect.TypeOf((*MockOrderServiceInterface)(nil).ListOrders), ctx, filter, cursor, limit)
 }
 
 // SaveOrder mocks base method.
-func (m *MockOrderServiceInterface) SaveOrder(msg []byte) error {
+func (m *MockOrderServiceInterface) SaveOrder(ctx context.Context, msg []byte) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveOrder", msg)
+	ret := m.ctrl.Call(m, "SaveOrder", ctx, msg)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SaveOrder indicates an expected call of SaveOrder.
-func (mr *MockOrderServiceInterfaceMockRecorder) SaveOrder(msg interface{}) *gomock.Call {
+func (mr *MockOrderServiceInterfaceMockRecorder) SaveOrder(ctx, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrder", reflect.TypeOf((*MockOrderServiceInterface)(nil).SaveOrder), ctx, msg)
+}
+
+// SaveOrders mocks base method.
+func (m *MockOrderServiceInterface) SaveOrders(ctx context.Context, msgs []consumer.Message) []error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOrders", ctx, msgs)
+	ret0, _ := ret[0].([]error)
+	return ret0
+}
+
+// SaveOrders indicates an expected call of SaveOrders.
+func (mr *MockOrderServiceInterfaceMockRecorder) SaveOrders(ctx, msgs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrders", reflect.TypeOf((*MockOrderServiceInterface)(nil).SaveOrders), ctx, msgs)
+}
+
+// Shutdown mocks base method.
+func (m *MockOrderServiceInterface) Shutdown(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shutdown", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockOrderServiceInterfaceMockRecorder) Shutdown(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockOrderServiceInterface)(nil).Shutdown), ctx)
+}
+
+// WarmupProgress mocks base method.
+func (m *MockOrderServiceInterface) WarmupProgress() service.WarmupProgress {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WarmupProgress")
+	ret0, _ := ret[0].(service.WarmupProgress)
+	return ret0
+}
+
+// WarmupProgress indicates an expected call of WarmupProgress.
+func (mr *MockOrderServiceInterfaceMockRecorder) WarmupProgress() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WarmupProgress", reflect.TypeOf((*MockOrderServiceInterface)(nil).WarmupProgress))
+}
+
+// MockIngestFailureServiceInterface is a mock of IngestFailureServiceInterface interface.
+type MockIngestFailureServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockIngestFailureServiceInterfaceMockRecorder
+}
+
+// MockIngestFailureServiceInterfaceMockRecorder is the mock recorder for MockIngestFailureServiceInterface.
+type MockIngestFailureServiceInterfaceMockRecorder struct {
+	mock *MockIngestFailureServiceInterface
+}
+
+// NewMockIngestFailureServiceInterface creates a new mock instance.
+func NewMockIngestFailureServiceInterface(ctrl *gomock.Controller) *MockIngestFailureServiceInterface {
+	mock := &MockIngestFailureServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockIngestFailureServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIngestFailureServiceInterface) EXPECT() *MockIngestFailureServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// DiscardIngestFailure mocks base method.
+func (m *MockIngestFailureServiceInterface) DiscardIngestFailure(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiscardIngestFailure", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DiscardIngestFailure indicates an expected call of DiscardIngestFailure.
+func (mr *MockIngestFailureServiceInterfaceMockRecorder) DiscardIngestFailure(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscardIngestFailure", reflect.TypeOf((*MockIngestFailureServiceInterface)(nil).DiscardIngestFailure), ctx, id)
+}
+
+// GetIngestFailure mocks base method.
+func (m *MockIngestFailureServiceInterface) GetIngestFailure(ctx context.Context, id int64) (*model.IngestFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIngestFailure", ctx, id)
+	ret0, _ := ret[0].(*model.IngestFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIngestFailure indicates an expected call of GetIngestFailure.
+func (mr *MockIngestFailureServiceInterfaceMockRecorder) GetIngestFailure(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIngestFailure", reflect.TypeOf((*MockIngestFailureServiceInterface)(nil).GetIngestFailure), ctx, id)
+}
+
+// ListIngestFailures mocks base method.
+func (m *MockIngestFailureServiceInterface) ListIngestFailures(ctx context.Context, limit, offset int) ([]model.IngestFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIngestFailures", ctx, limit, offset)
+	ret0, _ := ret[0].([]model.IngestFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIngestFailures indicates an expected call of ListIngestFailures.
+func (mr *MockIngestFailureServiceInterfaceMockRecorder) ListIngestFailures(ctx, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIngestFailures", reflect.TypeOf((*MockIngestFailureServiceInterface)(nil).ListIngestFailures), ctx, limit, offset)
+}
+
+// ReprocessIngestFailure mocks base method.
+func (m *MockIngestFailureServiceInterface) ReprocessIngestFailure(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReprocessIngestFailure", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReprocessIngestFailure indicates an expected call of ReprocessIngestFailure.
+func (mr *MockIngestFailureServiceInterfaceMockRecorder) ReprocessIngestFailure(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReprocessIngestFailure", reflect.TypeOf((*MockIngestFailureServiceInterface)(nil).ReprocessIngestFailure), ctx, id)
+}
+
+// UpdateIngestFailure mocks base method.
+func (m *MockIngestFailureServiceInterface) UpdateIngestFailure(ctx context.Context, id int64, payload string) (*model.IngestFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateIngestFailure", ctx, id, payload)
+	ret0, _ := ret[0].(*model.IngestFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateIngestFailure indicates an expected call of UpdateIngestFailure.
+func (mr *MockIngestFailureServiceInterfaceMockRecorder) UpdateIngestFailure(ctx, id, payload interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrder", reflect.TypeOf((*MockOrderServiceInterface)(nil).SaveOrder), msg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIngestFailure", reflect.TypeOf((*MockIngestFailureServiceInterface)(nil).UpdateIngestFailure), ctx, id, payload)
 }
@@ -3,15 +3,24 @@ package test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/karambo3a/wbtech_test_task/internal/apperror"
+	"github.com/karambo3a/wbtech_test_task/internal/cache"
+	"github.com/karambo3a/wbtech_test_task/internal/config"
+	"github.com/karambo3a/wbtech_test_task/internal/consumer"
+	"github.com/karambo3a/wbtech_test_task/internal/metrics"
 	"github.com/karambo3a/wbtech_test_task/internal/model"
 	"github.com/karambo3a/wbtech_test_task/internal/repository"
 	"github.com/karambo3a/wbtech_test_task/internal/service"
+	"github.com/karambo3a/wbtech_test_task/internal/validation"
 	mock "github.com/karambo3a/wbtech_test_task/test/mocks"
-	"github.com/redis/go-redis/v9"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,11 +31,12 @@ func TestServiceGetOrder(t *testing.T) {
 	mockGetOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
 	mockRepository := &repository.Repository{OrderRepositoryInterface: mockGetOrderRepository}
 	mockConsumer := mock.NewMockConsumer(ctrl)
-	mockRedisCache := mock.NewMockRedisCache(ctrl)
+	mockCache := mock.NewMockOrderCache(ctrl)
 
-	mockRedisCache.EXPECT().Init(mockRepository).Return(nil)
-	mockConsumer.EXPECT().StartConsuming(gomock.Any()).AnyTimes()
-	s := service.NewService(mockRepository, mockConsumer, mockRedisCache, int64(100))
+	mockGetOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(100)).Return([]*model.Order{}, nil)
+	mockConsumer.EXPECT().StartBatchConsuming(gomock.Any()).AnyTimes()
+	s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, config.Default())
+	waitWarmup(t, s)
 
 	testOrder := model.Order{
 		OrderUID:          "order_uid1",
@@ -78,42 +88,81 @@ func TestServiceGetOrder(t *testing.T) {
 		},
 	}
 
+	cached := make(chan struct{}, 1)
+
 	tests := []struct {
 		name          string
 		orderUID      string
 		mockBehavior  func()
-		expectedOrder model.Order
+		expectedOrder *model.Order
 		expectedErr   error
+		waitCache     bool
 	}{
 		{
 			name:     "order in cache successful",
 			orderUID: "order_uid1",
 			mockBehavior: func() {
-				mockRedisCache.EXPECT().Get(context.Background(), "order_uid1").Return(testOrder, nil)
+				mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&testOrder, time.Time{}, nil)
 			},
-			expectedOrder: testOrder,
+			expectedOrder: &testOrder,
 			expectedErr:   nil,
 		},
 		{
 			name:     "order not in cache, found in repository",
 			orderUID: "order_uid1",
 			mockBehavior: func() {
-				mockRedisCache.EXPECT().Get(context.Background(), "order_uid1").Return(model.Order{}, redis.Nil)
-				mockGetOrderRepository.EXPECT().GetOrder("order_uid1").Return(testOrder, nil)
-				mockRedisCache.EXPECT().Set(context.Background(), "order_uid1", testOrder, 24*time.Hour).Return(nil)
+				mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&model.Order{}, time.Time{}, apperror.Wrap(apperror.ErrNotFound, errors.New("cache miss for key=order_uid1")))
+				mockGetOrderRepository.EXPECT().GetOrder(gomock.Any(), "order_uid1").Return(&testOrder, nil)
+				mockCache.EXPECT().Set(gomock.Any(), "order_uid1", &testOrder, 24*time.Hour).DoAndReturn(
+					func(_ context.Context, _ string, _ *model.Order, _ time.Duration) error {
+						cached <- struct{}{}
+						return nil
+					})
 			},
-			expectedOrder: testOrder,
+			expectedOrder: &testOrder,
+			waitCache:     true,
 			expectedErr:   nil,
 		},
 		{
 			name:     "order not in cache and not in repository",
 			orderUID: "order_uid1",
 			mockBehavior: func() {
-				mockRedisCache.EXPECT().Get(context.Background(), "order_uid1").Return(model.Order{}, redis.Nil)
-				mockGetOrderRepository.EXPECT().GetOrder("order_uid1").Return(model.Order{}, errors.New("failed to get order"))
+				mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&model.Order{}, time.Time{}, apperror.Wrap(apperror.ErrNotFound, errors.New("cache miss for key=order_uid1")))
+				mockGetOrderRepository.EXPECT().GetOrder(gomock.Any(), "order_uid1").Return(&model.Order{}, apperror.Wrap(apperror.ErrNotFound, errors.New("order order_uid1 not found")))
+				mockCache.EXPECT().SetNotFound(gomock.Any(), "order_uid1", time.Minute).DoAndReturn(
+					func(context.Context, string, time.Duration) error {
+						cached <- struct{}{}
+						return nil
+					})
+			},
+			expectedOrder: &model.Order{},
+			waitCache:     true,
+			expectedErr:   apperror.ErrNotFound,
+		},
+		{
+			name:     "order cached as not found",
+			orderUID: "order_uid1",
+			mockBehavior: func() {
+				mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&model.Order{}, time.Time{}, apperror.Wrap(apperror.ErrNotFound, cache.ErrCachedNotFound))
+			},
+			expectedOrder: &model.Order{},
+			expectedErr:   apperror.ErrNotFound,
+		},
+		{
+			name:     "cache unavailable, read from repository",
+			orderUID: "order_uid1",
+			mockBehavior: func() {
+				mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&model.Order{}, time.Time{}, apperror.Wrap(apperror.ErrUnavailable, errors.New("connection refused")))
+				mockGetOrderRepository.EXPECT().GetOrder(gomock.Any(), "order_uid1").Return(&testOrder, nil)
+				mockCache.EXPECT().Set(gomock.Any(), "order_uid1", &testOrder, 24*time.Hour).DoAndReturn(
+					func(_ context.Context, _ string, _ *model.Order, _ time.Duration) error {
+						cached <- struct{}{}
+						return apperror.Wrap(apperror.ErrUnavailable, errors.New("connection refused"))
+					})
 			},
-			expectedOrder: model.Order{},
-			expectedErr:   errors.New("order with order_uid=order_uid1 is not found: failed to get order"),
+			expectedOrder: &testOrder,
+			waitCache:     true,
+			expectedErr:   nil,
 		},
 	}
 
@@ -121,12 +170,833 @@ func TestServiceGetOrder(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mockBehavior()
 
-			order, err := s.GetOrder(test.orderUID)
+			order, err := s.GetOrder(context.Background(), test.orderUID)
 
 			assert.Equal(t, test.expectedOrder, order)
-			if err != nil {
-				assert.Equal(t, test.expectedErr.Error(), err.Error())
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			if test.waitCache {
+				<-cached
 			}
 		})
 	}
 }
+
+func TestServiceSaveOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSaveOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+	mockRepository := &repository.Repository{OrderRepositoryInterface: mockSaveOrderRepository}
+	mockConsumer := mock.NewMockConsumer(ctrl)
+	mockCache := mock.NewMockOrderCache(ctrl)
+
+	mockSaveOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(100)).Return([]*model.Order{}, nil)
+	mockConsumer.EXPECT().StartBatchConsuming(gomock.Any()).AnyTimes()
+	s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, config.Default())
+	waitWarmup(t, s)
+
+	msg := newValidOrderJSON(t, "order_uid1")
+	cached := make(chan struct{}, 1)
+
+	tests := []struct {
+		name              string
+		msg               []byte
+		mockBehavior      func()
+		expectedErr       bool
+		expectedRetryable bool
+		waitCache         bool
+	}{
+		{
+			name: "saved",
+			msg:  msg,
+			mockBehavior: func() {
+				mockSaveOrderRepository.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(repository.SaveResultInserted, nil)
+				mockCache.EXPECT().Set(gomock.Any(), "order_uid1", gomock.Any(), 24*time.Hour).DoAndReturn(
+					func(_ context.Context, _ string, _ *model.Order, _ time.Duration) error {
+						cached <- struct{}{}
+						return nil
+					})
+			},
+			waitCache: true,
+		},
+		{
+			name:         "invalid order is permanent",
+			msg:          []byte(`{"order_uid":"order_uid1","items":[]}`),
+			mockBehavior: func() {},
+			expectedErr:  true,
+		},
+		{
+			name: "redelivered duplicate is skipped",
+			msg:  msg,
+			mockBehavior: func() {
+				mockSaveOrderRepository.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(repository.SaveResultDuplicate, nil)
+			},
+		},
+		{
+			name: "conflicting order is permanent",
+			msg:  msg,
+			mockBehavior: func() {
+				mockSaveOrderRepository.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(repository.SaveResultConflict, nil)
+			},
+			expectedErr: true,
+		},
+		{
+			name:         "invalid json is permanent",
+			msg:          []byte(`{"order_uid":`),
+			mockBehavior: func() {},
+			expectedErr:  true,
+		},
+		{
+			name: "database restart is retryable",
+			msg:  msg,
+			mockBehavior: func() {
+				mockSaveOrderRepository.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(repository.SaveResultFailed, apperror.Wrap(apperror.ErrUnavailable, &pgconn.PgError{Code: "57P01"}))
+			},
+			expectedErr:       true,
+			expectedRetryable: true,
+		},
+		{
+			name: "constraint violation is permanent",
+			msg:  msg,
+			mockBehavior: func() {
+				mockSaveOrderRepository.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(repository.SaveResultFailed, apperror.Wrap(apperror.ErrInvalidInput, &pgconn.PgError{Code: "23514"}))
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehavior()
+
+			err := s.SaveOrder(context.Background(), test.msg)
+
+			assert.Equal(t, test.expectedErr, err != nil)
+			assert.Equal(t, test.expectedRetryable, consumer.IsRetryable(err))
+			if test.waitCache {
+				<-cached
+			}
+		})
+	}
+}
+
+func TestServiceSaveOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSaveOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+	mockIngestFailureRepository := mock.NewMockIngestFailureRepositoryInterface(ctrl)
+	mockRepository := &repository.Repository{
+		OrderRepositoryInterface:         mockSaveOrderRepository,
+		IngestFailureRepositoryInterface: mockIngestFailureRepository,
+	}
+	mockConsumer := mock.NewMockConsumer(ctrl)
+	mockCache := mock.NewMockOrderCache(ctrl)
+
+	mockSaveOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(100)).Return([]*model.Order{}, nil)
+	mockConsumer.EXPECT().StartBatchConsuming(gomock.Any()).AnyTimes()
+	s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, config.Default())
+	waitWarmup(t, s)
+
+	msgs := []consumer.Message{
+		{Topic: "order", Offset: 1, Value: newValidOrderJSON(t, "order_uid1")},
+		{Topic: "order", Offset: 2, Value: []byte(`{"order_uid":`)},
+		{Topic: "order", Offset: 3, Value: newValidOrderJSON(t, "order_uid2")},
+	}
+	quarantinedAt := func(i int, err error) {
+		mockIngestFailureRepository.EXPECT().SaveFailure(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, failure *model.IngestFailure) (int64, error) {
+				assert.Equal(t, msgs[i].Offset, failure.Offset)
+				assert.Equal(t, string(msgs[i].Value), failure.Payload)
+				return int64(i + 1), err
+			})
+	}
+	quarantined := func(err error) { quarantinedAt(1, err) }
+	var cached sync.WaitGroup
+
+	tests := []struct {
+		name              string
+		mockBehavior      func()
+		expectedErr       []bool
+		expectedRetryable []bool
+	}{
+		{
+			name: "batch saved, invalid json quarantined",
+			mockBehavior: func() {
+				quarantined(nil)
+				mockSaveOrderRepository.EXPECT().SaveOrders(gomock.Any(), gomock.Len(2)).Return([]repository.SaveResult{repository.SaveResultInserted, repository.SaveResultInserted}, nil)
+				cached.Add(2)
+				mockCache.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), 24*time.Hour).DoAndReturn(
+					func(_ context.Context, _ string, _ *model.Order, _ time.Duration) error {
+						cached.Done()
+						return nil
+					}).Times(2)
+			},
+			expectedErr:       []bool{false, false, false},
+			expectedRetryable: []bool{false, false, false},
+		},
+		{
+			name: "duplicate and conflict in batch",
+			mockBehavior: func() {
+				quarantined(nil)
+				mockSaveOrderRepository.EXPECT().SaveOrders(gomock.Any(), gomock.Len(2)).Return([]repository.SaveResult{repository.SaveResultDuplicate, repository.SaveResultConflict}, nil)
+			},
+			expectedErr:       []bool{false, false, true},
+			expectedRetryable: []bool{false, false, false},
+		},
+		{
+			name: "batch rejected, orders saved one by one",
+			mockBehavior: func() {
+				quarantined(errors.New("value too long"))
+				mockSaveOrderRepository.EXPECT().SaveOrders(gomock.Any(), gomock.Len(2)).Return(nil, apperror.Wrap(apperror.ErrInvalidInput, &pgconn.PgError{Code: "23514"}))
+				// the order rejected by a constraint is quarantined instead of going to the dead-letter topic
+				mockSaveOrderRepository.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(repository.SaveResultFailed, apperror.Wrap(apperror.ErrInvalidInput, &pgconn.PgError{Code: "23514"}))
+				quarantinedAt(0, nil)
+				mockSaveOrderRepository.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(repository.SaveResultInserted, nil)
+				cached.Add(1)
+				mockCache.EXPECT().Set(gomock.Any(), "order_uid2", gomock.Any(), 24*time.Hour).DoAndReturn(
+					func(_ context.Context, _ string, _ *model.Order, _ time.Duration) error {
+						cached.Done()
+						return nil
+					})
+			},
+			expectedErr:       []bool{false, true, false},
+			expectedRetryable: []bool{false, false, false},
+		},
+		{
+			name: "order rejected by a constraint is retried when quarantine is unavailable",
+			mockBehavior: func() {
+				quarantined(nil)
+				mockSaveOrderRepository.EXPECT().SaveOrders(gomock.Any(), gomock.Len(2)).Return(nil, apperror.Wrap(apperror.ErrInvalidInput, &pgconn.PgError{Code: "23505"}))
+				mockSaveOrderRepository.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(repository.SaveResultFailed, apperror.Wrap(apperror.ErrInvalidInput, &pgconn.PgError{Code: "23505"}))
+				quarantinedAt(0, apperror.Wrap(apperror.ErrUnavailable, &pgconn.PgError{Code: "57P01"}))
+				mockSaveOrderRepository.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(repository.SaveResultDuplicate, nil)
+			},
+			expectedErr:       []bool{true, false, false},
+			expectedRetryable: []bool{true, false, false},
+		},
+		{
+			name: "database restart is retryable for the whole batch",
+			mockBehavior: func() {
+				quarantined(apperror.Wrap(apperror.ErrUnavailable, &pgconn.PgError{Code: "57P01"}))
+				mockSaveOrderRepository.EXPECT().SaveOrders(gomock.Any(), gomock.Len(2)).Return(nil, apperror.Wrap(apperror.ErrUnavailable, &pgconn.PgError{Code: "57P01"}))
+			},
+			expectedErr:       []bool{true, true, true},
+			expectedRetryable: []bool{true, true, true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehavior()
+
+			errs := s.SaveOrders(context.Background(), msgs)
+
+			assert.Len(t, errs, len(msgs))
+			for i, err := range errs {
+				assert.Equal(t, test.expectedErr[i], err != nil)
+				assert.Equal(t, test.expectedRetryable[i], consumer.IsRetryable(err))
+			}
+			cached.Wait()
+		})
+	}
+}
+
+func TestServiceShutdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+	mockRepository := &repository.Repository{OrderRepositoryInterface: mockOrderRepository}
+	mockConsumer := mock.NewMockConsumer(ctrl)
+	mockCache := mock.NewMockOrderCache(ctrl)
+
+	mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(100)).Return([]*model.Order{}, nil)
+	mockConsumer.EXPECT().StartBatchConsuming(gomock.Any())
+	s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, config.Default())
+	waitWarmup(t, s)
+
+	setStarted := make(chan struct{})
+	releaseSet := make(chan struct{})
+	mockOrderRepository.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(repository.SaveResultInserted, nil)
+	mockCache.EXPECT().Set(gomock.Any(), "order_uid1", gomock.Any(), 24*time.Hour).DoAndReturn(
+		func(_ context.Context, _ string, _ *model.Order, _ time.Duration) error {
+			close(setStarted)
+			<-releaseSet
+			return nil
+		})
+	mockConsumer.EXPECT().Close(gomock.Any()).Return(nil).Times(2)
+
+	assert.NoError(t, s.SaveOrder(context.Background(), newValidOrderJSON(t, "order_uid1")))
+	<-setStarted
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Shutdown(ctx), context.DeadlineExceeded)
+
+	close(releaseSet)
+	assert.NoError(t, s.Shutdown(context.Background()))
+}
+
+func TestServiceShutdownConsumerCloseError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+	mockRepository := &repository.Repository{OrderRepositoryInterface: mockOrderRepository}
+	mockConsumer := mock.NewMockConsumer(ctrl)
+	mockCache := mock.NewMockOrderCache(ctrl)
+
+	mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(100)).Return([]*model.Order{}, nil)
+	mockConsumer.EXPECT().StartBatchConsuming(gomock.Any())
+	s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, config.Default())
+	waitWarmup(t, s)
+
+	setStarted := make(chan struct{})
+	setFinished := make(chan struct{})
+	mockOrderRepository.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(repository.SaveResultInserted, nil)
+	mockCache.EXPECT().Set(gomock.Any(), "order_uid1", gomock.Any(), 24*time.Hour).DoAndReturn(
+		func(_ context.Context, _ string, _ *model.Order, _ time.Duration) error {
+			close(setStarted)
+			time.Sleep(10 * time.Millisecond)
+			close(setFinished)
+			return nil
+		})
+	closeErr := errors.New("failed to commit offsets")
+	mockConsumer.EXPECT().Close(gomock.Any()).Return(closeErr)
+
+	assert.NoError(t, s.SaveOrder(context.Background(), newValidOrderJSON(t, "order_uid1")))
+	<-setStarted
+
+	// pending cache writes are still awaited when the consumer fails to close
+	assert.ErrorIs(t, s.Shutdown(context.Background()), closeErr)
+	select {
+	case <-setFinished:
+	default:
+		t.Fatal("shutdown returned before pending cache writes finished")
+	}
+}
+
+func TestServiceReprocessIngestFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+	mockIngestFailureRepository := mock.NewMockIngestFailureRepositoryInterface(ctrl)
+	mockRepository := &repository.Repository{
+		OrderRepositoryInterface:         mockOrderRepository,
+		IngestFailureRepositoryInterface: mockIngestFailureRepository,
+	}
+	mockConsumer := mock.NewMockConsumer(ctrl)
+	mockCache := mock.NewMockOrderCache(ctrl)
+
+	mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(100)).Return([]*model.Order{}, nil)
+	mockConsumer.EXPECT().StartBatchConsuming(gomock.Any())
+	s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, config.Default())
+	waitWarmup(t, s)
+
+	t.Run("fixed payload is saved and entry removed", func(t *testing.T) {
+		cached := make(chan struct{})
+		mockIngestFailureRepository.EXPECT().GetFailure(gomock.Any(), int64(1)).DoAndReturn(
+			func(ctx context.Context, _ int64) (*model.IngestFailure, error) {
+				_, ok := ctx.Deadline()
+				assert.True(t, ok, "reprocessing is bounded by the database timeout")
+				return &model.IngestFailure{ID: 1, Payload: string(newValidOrderJSON(t, "order_uid1"))}, nil
+			})
+		mockOrderRepository.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(repository.SaveResultInserted, nil)
+		mockCache.EXPECT().Set(gomock.Any(), "order_uid1", gomock.Any(), 24*time.Hour).DoAndReturn(
+			func(_ context.Context, _ string, _ *model.Order, _ time.Duration) error {
+				close(cached)
+				return nil
+			})
+		mockIngestFailureRepository.EXPECT().DeleteFailure(gomock.Any(), int64(1)).Return(nil)
+
+		assert.NoError(t, s.ReprocessIngestFailure(context.Background(), 1))
+		<-cached
+	})
+
+	t.Run("invalid payload keeps entry with new error", func(t *testing.T) {
+		mockIngestFailureRepository.EXPECT().GetFailure(gomock.Any(), int64(2)).Return(&model.IngestFailure{ID: 2, Payload: `{"order_uid":"order_uid2"}`}, nil)
+		mockIngestFailureRepository.EXPECT().UpdateFailureError(gomock.Any(), int64(2), gomock.Any()).Return(nil)
+
+		var validationErr *validation.Error
+		assert.ErrorAs(t, s.ReprocessIngestFailure(context.Background(), 2), &validationErr)
+	})
+}
+
+func TestServiceListOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+	mockRepository := &repository.Repository{OrderRepositoryInterface: mockOrderRepository}
+	mockConsumer := mock.NewMockConsumer(ctrl)
+	mockCache := mock.NewMockOrderCache(ctrl)
+
+	mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(100)).Return([]*model.Order{}, nil)
+	mockConsumer.EXPECT().StartBatchConsuming(gomock.Any())
+	s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, config.Default())
+	waitWarmup(t, s)
+
+	filter := model.OrderFilter{CustomerID: "customer_1"}
+	newest, middle, oldest := newValidOrder("order_uid3"), newValidOrder("order_uid2"), newValidOrder("order_uid1")
+	middle.DateCreated = newest.DateCreated.Add(-time.Hour)
+	oldest.DateCreated = newest.DateCreated.Add(-2 * time.Hour)
+
+	mockOrderRepository.EXPECT().ListOrders(gomock.Any(), filter, nil, 3).Return([]*model.Order{&newest, &middle, &oldest}, nil)
+	page, err := s.ListOrders(context.Background(), filter, "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.Order{&newest, &middle}, page.Orders)
+	assert.NotEmpty(t, page.NextCursor)
+
+	after := &model.OrderCursor{DateCreated: middle.DateCreated, OrderUID: middle.OrderUID}
+	mockOrderRepository.EXPECT().ListOrders(gomock.Any(), filter, gomock.Eq(after), 3).Return([]*model.Order{&oldest}, nil)
+	page, err = s.ListOrders(context.Background(), filter, page.NextCursor, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.Order{&oldest}, page.Orders)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = s.ListOrders(context.Background(), filter, "not a cursor", 2)
+	assert.ErrorIs(t, err, apperror.ErrInvalidInput)
+}
+
+func TestServiceGetOrderContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+	mockRepository := &repository.Repository{OrderRepositoryInterface: mockOrderRepository}
+	mockConsumer := mock.NewMockConsumer(ctrl)
+	mockCache := mock.NewMockOrderCache(ctrl)
+
+	mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(100)).Return([]*model.Order{}, nil)
+	mockConsumer.EXPECT().StartBatchConsuming(gomock.Any())
+	s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, config.Default())
+	waitWarmup(t, s)
+
+	type ctxKey struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "request"))
+	cancel()
+
+	order := newValidOrder("order_uid1")
+	loaded := make(chan struct{})
+	cached := make(chan struct{})
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").DoAndReturn(
+		func(ctx context.Context, _ string) (*model.Order, time.Time, error) {
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			return &model.Order{}, time.Time{}, apperror.Wrap(apperror.ErrNotFound, ctx.Err())
+		})
+	mockOrderRepository.EXPECT().GetOrder(gomock.Any(), "order_uid1").DoAndReturn(
+		func(ctx context.Context, _ string) (*model.Order, error) {
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			assert.Equal(t, "request", ctx.Value(ctxKey{}))
+			// other requests may wait for the same read, so it is not cancelled with the request
+			<-loaded
+			assert.NoError(t, ctx.Err())
+			return &order, nil
+		})
+	// the cache write outlives the request
+	mockCache.EXPECT().Set(gomock.Any(), "order_uid1", gomock.Any(), 24*time.Hour).DoAndReturn(
+		func(ctx context.Context, _ string, _ *model.Order, _ time.Duration) error {
+			defer close(cached)
+			assert.NoError(t, ctx.Err())
+			assert.Equal(t, "request", ctx.Value(ctxKey{}))
+			return nil
+		})
+
+	// the cancelled request stops waiting for the read
+	_, err := s.GetOrder(ctx, "order_uid1")
+	assert.ErrorIs(t, err, apperror.ErrUnavailable)
+	assert.ErrorIs(t, err, context.Canceled)
+	close(loaded)
+	<-cached
+}
+
+func TestServiceGetOrderSharedLoad(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+	mockRepository := &repository.Repository{OrderRepositoryInterface: mockOrderRepository}
+	mockConsumer := mock.NewMockConsumer(ctrl)
+	mockCache := mock.NewMockOrderCache(ctrl)
+
+	mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(100)).Return([]*model.Order{}, nil)
+	mockConsumer.EXPECT().StartBatchConsuming(gomock.Any())
+	s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, config.Default())
+	waitWarmup(t, s)
+
+	const requests = 10
+	order := newValidOrder("order_uid1")
+	missed := make(chan struct{}, requests)
+	loaded := make(chan struct{})
+	cached := make(chan struct{})
+
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").DoAndReturn(
+		func(context.Context, string) (*model.Order, time.Time, error) {
+			missed <- struct{}{}
+			return &model.Order{}, time.Time{}, apperror.Wrap(apperror.ErrNotFound, errors.New("cache miss for key=order_uid1"))
+		}).Times(requests)
+	// concurrent misses wait for a single read and a single cache write
+	mockOrderRepository.EXPECT().GetOrder(gomock.Any(), "order_uid1").DoAndReturn(
+		func(context.Context, string) (*model.Order, error) {
+			<-loaded
+			return &order, nil
+		})
+	mockCache.EXPECT().Set(gomock.Any(), "order_uid1", &order, 24*time.Hour).DoAndReturn(
+		func(context.Context, string, *model.Order, time.Duration) error {
+			close(cached)
+			return nil
+		})
+
+	var wg sync.WaitGroup
+	for range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := s.GetOrder(context.Background(), "order_uid1")
+			assert.NoError(t, err)
+			assert.Equal(t, &order, got)
+		}()
+	}
+	for range requests {
+		<-missed
+	}
+	// let the misses join the read before it completes
+	time.Sleep(50 * time.Millisecond)
+	close(loaded)
+	wg.Wait()
+	<-cached
+}
+
+func TestServiceGetOrderEarlyRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+	mockRepository := &repository.Repository{OrderRepositoryInterface: mockOrderRepository}
+	mockConsumer := mock.NewMockConsumer(ctrl)
+	mockCache := mock.NewMockOrderCache(ctrl)
+
+	cfg := config.Default()
+	cfg.Cache.EarlyRefreshWindow = time.Minute
+	mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(100)).Return([]*model.Order{}, nil)
+	mockConsumer.EXPECT().StartBatchConsuming(gomock.Any())
+	s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, cfg)
+	waitWarmup(t, s)
+
+	cachedOrder := newValidOrder("order_uid1")
+	storedOrder := newValidOrder("order_uid1")
+	storedOrder.TrackNumber = "NEWTRACK"
+
+	// orders outside the refresh window and without expiration are not reloaded
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&cachedOrder, time.Now().Add(time.Hour), nil)
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&cachedOrder, time.Time{}, nil)
+	for range 2 {
+		got, err := s.GetOrder(context.Background(), "order_uid1")
+		assert.NoError(t, err)
+		assert.Equal(t, &cachedOrder, got)
+	}
+
+	// an order about to expire is served from the cache and reloaded in the background
+	refreshed := make(chan struct{})
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&cachedOrder, time.Now(), nil)
+	mockOrderRepository.EXPECT().GetOrder(gomock.Any(), "order_uid1").Return(&storedOrder, nil)
+	mockCache.EXPECT().Set(gomock.Any(), "order_uid1", &storedOrder, 24*time.Hour).DoAndReturn(
+		func(context.Context, string, *model.Order, time.Duration) error {
+			close(refreshed)
+			return nil
+		})
+	got, err := s.GetOrder(context.Background(), "order_uid1")
+	assert.NoError(t, err)
+	assert.Equal(t, &cachedOrder, got)
+	<-refreshed
+}
+
+func TestServiceGetOrderBloomFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+	mockRepository := &repository.Repository{OrderRepositoryInterface: mockOrderRepository}
+	mockConsumer := mock.NewMockConsumer(ctrl)
+	mockCache := mock.NewMockOrderCache(ctrl)
+
+	cfg := config.Default()
+	cfg.Cache.BloomEnabled = true
+	mockOrderRepository.EXPECT().ForEachOrderUID(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, fn func(string)) error {
+			fn("order_uid1")
+			return nil
+		})
+	mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(100)).Return([]*model.Order{}, nil)
+	mockConsumer.EXPECT().StartBatchConsuming(gomock.Any())
+	s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, cfg)
+	waitWarmup(t, s)
+
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid3").Return(&model.Order{}, time.Time{}, apperror.Wrap(apperror.ErrNotFound, cache.ErrCachedNotFound)).AnyTimes()
+	waitBloomFilter(t, s, "order_uid3")
+
+	order1 := newValidOrder("order_uid1")
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid1").Return(&order1, time.Time{}, nil)
+	found := bloomMisses("found")
+	got, err := s.GetOrder(context.Background(), "order_uid1")
+	assert.NoError(t, err)
+	assert.Equal(t, &order1, got)
+	assert.Equal(t, found, bloomMisses("found"))
+
+	// an order saved by another instance is still found and becomes known
+	order2 := newValidOrder("order_uid2")
+	cached := make(chan struct{})
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid2").Return(&model.Order{}, time.Time{}, apperror.Wrap(apperror.ErrNotFound, errors.New("cache miss")))
+	mockOrderRepository.EXPECT().GetOrder(gomock.Any(), "order_uid2").Return(&order2, nil)
+	mockCache.EXPECT().Set(gomock.Any(), "order_uid2", gomock.Any(), 24*time.Hour).DoAndReturn(
+		func(context.Context, string, *model.Order, time.Duration) error {
+			close(cached)
+			return nil
+		})
+	got, err = s.GetOrder(context.Background(), "order_uid2")
+	assert.NoError(t, err)
+	assert.Equal(t, &order2, got)
+	assert.Equal(t, found+1, bloomMisses("found"))
+	<-cached
+
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid2").Return(&order2, time.Time{}, nil)
+	_, err = s.GetOrder(context.Background(), "order_uid2")
+	assert.NoError(t, err)
+	assert.Equal(t, found+1, bloomMisses("found"))
+
+	// saved orders become known
+	saved := make(chan struct{})
+	mockOrderRepository.EXPECT().SaveOrder(gomock.Any(), gomock.Any()).Return(repository.SaveResultInserted, nil)
+	mockCache.EXPECT().Set(gomock.Any(), "order_uid4", gomock.Any(), 24*time.Hour).DoAndReturn(
+		func(context.Context, string, *model.Order, time.Duration) error {
+			close(saved)
+			return nil
+		})
+	assert.NoError(t, s.SaveOrder(context.Background(), newValidOrderJSON(t, "order_uid4")))
+	<-saved
+
+	order4 := newValidOrder("order_uid4")
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid4").Return(&order4, time.Time{}, nil)
+	_, err = s.GetOrder(context.Background(), "order_uid4")
+	assert.NoError(t, err)
+	assert.Equal(t, found+1, bloomMisses("found"))
+}
+
+func TestServiceGetOrderBloomFilterRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+	mockRepository := &repository.Repository{OrderRepositoryInterface: mockOrderRepository}
+	mockConsumer := mock.NewMockConsumer(ctrl)
+	mockCache := mock.NewMockOrderCache(ctrl)
+
+	cfg := config.Default()
+	cfg.Cache.BloomEnabled = true
+	cfg.Cache.BloomRefreshInterval = 10 * time.Millisecond
+	refreshes := make(chan struct{}, 2)
+	// a failed load does not stop the service, the filter is loaded by a later refresh
+	gomock.InOrder(
+		mockOrderRepository.EXPECT().ForEachOrderUID(gomock.Any(), gomock.Any()).Return(apperror.Wrap(apperror.ErrUnavailable, errors.New("connection refused"))),
+		mockOrderRepository.EXPECT().ForEachOrderUID(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, fn func(string)) error {
+				fn("order_uid1")
+				return nil
+			}),
+		// an order saved by another instance becomes known after a refresh
+		mockOrderRepository.EXPECT().ForEachOrderUID(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, fn func(string)) error {
+				select {
+				case refreshes <- struct{}{}:
+				default:
+				}
+				fn("order_uid1")
+				fn("order_uid2")
+				return nil
+			}).MinTimes(2),
+	)
+	mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(100)).Return([]*model.Order{}, nil)
+	mockConsumer.EXPECT().StartBatchConsuming(gomock.Any())
+	s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, cfg)
+	waitWarmup(t, s)
+
+	// the filter of a refresh is stored before the next one starts
+	<-refreshes
+	<-refreshes
+	order2 := newValidOrder("order_uid2")
+	mockCache.EXPECT().Get(gomock.Any(), "order_uid2").Return(&order2, time.Time{}, nil)
+	found := bloomMisses("found")
+	_, err := s.GetOrder(context.Background(), "order_uid2")
+	assert.NoError(t, err)
+	assert.Equal(t, found, bloomMisses("found"))
+
+	mockConsumer.EXPECT().Close(gomock.Any()).Return(nil)
+	assert.NoError(t, s.Shutdown(context.Background()))
+}
+
+func TestServiceMemoryCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+	mockRepository := &repository.Repository{OrderRepositoryInterface: mockOrderRepository}
+	mockConsumer := mock.NewMockConsumer(ctrl)
+
+	order1 := newValidOrder("order_uid1")
+	order2 := newValidOrder("order_uid2")
+	mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(100)).Return([]*model.Order{&order1}, nil)
+	mockConsumer.EXPECT().StartBatchConsuming(gomock.Any())
+	memory := cache.NewMemoryCache(100, 1<<20)
+	s := service.NewService(context.Background(), mockRepository, mockConsumer, memory, config.Default())
+	waitWarmup(t, s)
+
+	// warmed up orders are served without the database
+	got, err := s.GetOrder(context.Background(), "order_uid1")
+	assert.NoError(t, err)
+	assert.Equal(t, &order1, got)
+
+	// an order read from the database is cached in the background
+	mockOrderRepository.EXPECT().GetOrder(gomock.Any(), "order_uid2").Return(&order2, nil)
+	got, err = s.GetOrder(context.Background(), "order_uid2")
+	assert.NoError(t, err)
+	assert.Equal(t, &order2, got)
+
+	// so is an unknown order
+	mockOrderRepository.EXPECT().GetOrder(gomock.Any(), "order_uid3").Return(&model.Order{}, apperror.Wrap(apperror.ErrNotFound, errors.New("order order_uid3 not found")))
+	_, err = s.GetOrder(context.Background(), "order_uid3")
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+
+	assert.Eventually(t, func() bool {
+		_, _, err2 := memory.Get(context.Background(), "order_uid2")
+		_, _, err3 := memory.Get(context.Background(), "order_uid3")
+		return err2 == nil && errors.Is(err3, cache.ErrCachedNotFound)
+	}, time.Second, 10*time.Millisecond)
+
+	got, err = s.GetOrder(context.Background(), "order_uid2")
+	assert.NoError(t, err)
+	assert.Equal(t, &order2, got)
+	_, err = s.GetOrder(context.Background(), "order_uid3")
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+}
+
+func TestServiceWarmup(t *testing.T) {
+	orders := make([]*model.Order, 5)
+	for i := range orders {
+		order := newValidOrder(fmt.Sprintf("order_uid%d", i))
+		orders[i] = &order
+	}
+
+	newCfg := func(strategy string) *config.Config {
+		cfg := config.Default()
+		cfg.Cache.WarmupStrategy = strategy
+		cfg.Cache.WarmupLimit = 5
+		cfg.Cache.WarmupPageSize = 2
+		cfg.Cache.WarmupConcurrency = 2
+		return cfg
+	}
+
+	t.Run("recent orders in pages", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+		mockRepository := &repository.Repository{OrderRepositoryInterface: mockOrderRepository}
+		mockConsumer := mock.NewMockConsumer(ctrl)
+		mockCache := mock.NewMockOrderCache(ctrl)
+
+		mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(2)).Return(orders[:2], nil)
+		mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(2), int64(2)).Return(orders[2:4], nil)
+		mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(4), int64(1)).Return(orders[4:], nil)
+		mockCache.EXPECT().SetMany(gomock.Any(), orders[:2], 24*time.Hour).Return(nil)
+		mockCache.EXPECT().SetMany(gomock.Any(), orders[2:4], 24*time.Hour).Return(nil)
+		mockCache.EXPECT().SetMany(gomock.Any(), orders[4:], 24*time.Hour).Return(nil)
+		mockConsumer.EXPECT().StartBatchConsuming(gomock.Any())
+		s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, newCfg(service.WarmupRecent))
+		waitWarmup(t, s)
+
+		assert.Equal(t, service.WarmupProgress{State: service.WarmupDone, Strategy: service.WarmupRecent, Limit: 5, Cached: 5}, s.WarmupProgress())
+	})
+
+	t.Run("frequent orders topped up with recent ones", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+		mockRepository := &repository.Repository{OrderRepositoryInterface: mockOrderRepository}
+		mockConsumer := mock.NewMockConsumer(ctrl)
+		mockCache := mock.NewMockOrderCache(ctrl)
+
+		mockOrderRepository.EXPECT().GetMostAccessedOrders(gomock.Any(), int64(0), int64(2)).Return(orders[:1], nil)
+		mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(2)).Return(orders[1:3], nil)
+		mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(2), int64(2)).Return([]*model.Order{}, nil)
+		mockCache.EXPECT().SetMany(gomock.Any(), orders[:1], 24*time.Hour).Return(nil)
+		mockCache.EXPECT().SetMany(gomock.Any(), orders[1:3], 24*time.Hour).Return(errors.New("redis is down"))
+		mockConsumer.EXPECT().StartBatchConsuming(gomock.Any())
+		s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, newCfg(service.WarmupFrequent))
+		waitWarmup(t, s)
+
+		assert.Equal(t, service.WarmupProgress{State: service.WarmupDone, Strategy: service.WarmupFrequent, Limit: 5, Cached: 1, Failed: 2}, s.WarmupProgress())
+
+		// reads are counted and saved on shutdown
+		mockCache.EXPECT().Get(gomock.Any(), "order_uid0").Return(orders[0], time.Time{}, nil).Times(2)
+		for range 2 {
+			_, err := s.GetOrder(context.Background(), "order_uid0")
+			assert.NoError(t, err)
+		}
+		mockConsumer.EXPECT().Close(gomock.Any()).Return(nil)
+		mockOrderRepository.EXPECT().AddOrderAccesses(gomock.Any(), map[string]int64{"order_uid0": 2}).Return(nil)
+		assert.NoError(t, s.Shutdown(context.Background()))
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockOrderRepository := mock.NewMockOrderRepositoryInterface(ctrl)
+		mockRepository := &repository.Repository{OrderRepositoryInterface: mockOrderRepository}
+		mockConsumer := mock.NewMockConsumer(ctrl)
+		mockCache := mock.NewMockOrderCache(ctrl)
+
+		mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(0), int64(2)).Return(orders[:2], nil)
+		mockOrderRepository.EXPECT().GetRecentOrders(gomock.Any(), int64(2), int64(2)).Return(nil, apperror.Wrap(apperror.ErrUnavailable, errors.New("connection refused")))
+		mockCache.EXPECT().SetMany(gomock.Any(), orders[:2], 24*time.Hour).Return(nil)
+		mockConsumer.EXPECT().StartBatchConsuming(gomock.Any())
+		s := service.NewService(context.Background(), mockRepository, mockConsumer, mockCache, newCfg(service.WarmupRecent))
+		waitWarmup(t, s)
+
+		progress := s.WarmupProgress()
+		assert.Equal(t, service.WarmupFailed, progress.State)
+		assert.Equal(t, int64(2), progress.Cached)
+		assert.Contains(t, progress.Error, "connection refused")
+	})
+}
+
+// waitWarmup waits for the background cache warm-up, so its calls do not interleave with the ones under test.
+func waitWarmup(t *testing.T, s *service.Service) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		return s.WarmupProgress().State != service.WarmupRunning
+	}, time.Second, time.Millisecond)
+}
+
+// waitBloomFilter waits for the Bloom filter to be loaded, which is when it misses the unknown order.
+func waitBloomFilter(t *testing.T, s *service.Service, unknownOrderUID string) {
+	t.Helper()
+	assert.Eventually(t, func() bool {
+		notFound := bloomMisses("not_found")
+		_, err := s.GetOrder(context.Background(), unknownOrderUID)
+		return errors.Is(err, apperror.ErrNotFound) && bloomMisses("not_found") > notFound
+	}, time.Second, time.Millisecond)
+}
+
+func bloomMisses(result string) float64 {
+	return testutil.ToFloat64(metrics.BloomMisses.WithLabelValues(result))
+}
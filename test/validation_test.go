@@ -0,0 +1,171 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/karambo3a/wbtech_test_task/internal/model"
+	"github.com/karambo3a/wbtech_test_task/internal/validation"
+	"github.com/stretchr/testify/assert"
+)
+
+func newValidOrder(orderUID string) model.Order {
+	return model.Order{
+		OrderUID:          orderUID,
+		TrackNumber:       "WBILMTESTTRACK",
+		Entry:             "WBIL",
+		Locale:            "en",
+		InternalSignature: "",
+		CustomerID:        "test",
+		DeliveryService:   "meest",
+		Shardkey:          "9",
+		SmID:              99,
+		DateCreated:       time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:          "1",
+		Delivery: model.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: model.Payment{
+			Transaction:  orderUID,
+			RequestID:    "",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDt:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+			CustomFee:    0,
+		},
+		Items: []model.Item{
+			{
+				ChrtID:      9934930,
+				TrackNumber: "WBILMTESTTRACK",
+				Price:       453,
+				Rid:         "ab4219087a764ae0btest",
+				Name:        "Mascaras",
+				Sale:        30,
+				Size:        "0",
+				TotalPrice:  317,
+				NmID:        2389212,
+				Brand:       "Vivienne Sabo",
+				Status:      202,
+			},
+		},
+	}
+}
+
+func newValidOrderJSON(t *testing.T, orderUID string) []byte {
+	msg, err := json.Marshal(newValidOrder(orderUID))
+	if err != nil {
+		t.Fatalf("failed to marshal order: %v", err)
+	}
+	return msg
+}
+
+func TestValidateOrder(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		modify         func(order *model.Order)
+		expectedFields []string
+	}{
+		{
+			name:   "valid",
+			modify: func(order *model.Order) {},
+		},
+		{
+			name: "required fields",
+			modify: func(order *model.Order) {
+				order.OrderUID = ""
+				order.Delivery.City = " "
+				order.Payment.Transaction = ""
+			},
+			expectedFields: []string{"order_uid", "delivery.city", "payment.transaction"},
+		},
+		{
+			name: "no items",
+			modify: func(order *model.Order) {
+				order.Items = nil
+				order.Payment.GoodsTotal = 0
+				order.Payment.Amount = 1500
+			},
+			expectedFields: []string{"items"},
+		},
+		{
+			name: "payment totals",
+			modify: func(order *model.Order) {
+				order.Payment.Amount = 1000
+				order.Payment.GoodsTotal = 300
+			},
+			expectedFields: []string{"payment.goods_total", "payment.amount"},
+		},
+		{
+			name: "item total_price, sale and track_number",
+			modify: func(order *model.Order) {
+				order.Items[0].TotalPrice = 400
+				order.Items[0].TrackNumber = "OTHER"
+				order.Payment.GoodsTotal = 400
+				order.Payment.Amount = 1900
+			},
+			expectedFields: []string{"items[0].track_number", "items[0].total_price"},
+		},
+		{
+			name: "sale out of range",
+			modify: func(order *model.Order) {
+				order.Items[0].Sale = 120
+				order.Items[0].TotalPrice = -90
+				order.Payment.GoodsTotal = -90
+				order.Payment.Amount = 1410
+			},
+			expectedFields: []string{"payment.goods_total", "items[0].sale"},
+		},
+		{
+			name: "currency, phone and email format",
+			modify: func(order *model.Order) {
+				order.Payment.Currency = "usd"
+				order.Delivery.Phone = "8 800 555 35 35"
+				order.Delivery.Email = "test.gmail.com"
+			},
+			expectedFields: []string{"delivery.phone", "delivery.email", "payment.currency"},
+		},
+		{
+			name: "date_created in the future",
+			modify: func(order *model.Order) {
+				order.DateCreated = now.Add(time.Hour)
+			},
+			expectedFields: []string{"date_created"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			order := newValidOrder("b563feb7b2b84b6test")
+			test.modify(&order)
+
+			err := validation.ValidateOrder(&order, now)
+
+			if len(test.expectedFields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *validation.Error
+			if !assert.ErrorAs(t, err, &validationErr) {
+				return
+			}
+			fields := make([]string, 0, len(validationErr.Violations))
+			for _, violation := range validationErr.Violations {
+				fields = append(fields, violation.Field)
+			}
+			assert.ElementsMatch(t, test.expectedFields, fields)
+		})
+	}
+}